@@ -0,0 +1,143 @@
+package config
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// WithAdminToken is an option to require the bearer token for the reload
+// endpoint of Handler and ReloadHandler, e.g. "Authorization: Bearer s3cr3t".
+func WithAdminToken[T any](token string) Option[T] {
+	return func(cl *loader[T]) {
+		cl.adminToken = token
+	}
+}
+
+// adminSnapshot is a version of the history in the response of the admin
+// handler, without the config.
+type adminSnapshot struct {
+	Version  int    `json:"version"`
+	Time     string `json:"time"`
+	Checksum string `json:"checksum"`
+}
+
+// Handler returns an http.Handler for an internal admin server, GET /config
+// responds with the effective config with the values of secret fields masked,
+// the provenance of the keys, the status of the last reload and the versions
+// of the history as JSON. POST /config/reload reloads the config like
+// ReloadHandler. Mount it on the root of the admin mux or on /config and
+// /config/.
+func (c *loader[T]) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /config", c.serveConfig)
+	mux.Handle("POST /config/reload", c.ReloadHandler())
+
+	return mux
+}
+
+// serveConfig serves the config, its provenance, status and history.
+func (c *loader[T]) serveConfig(w http.ResponseWriter, _ *http.Request) {
+	settings, err := c.settings()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	c.redactSettings(settings, c.subSection)
+
+	provenance := make(map[string]string)
+	for key, info := range c.Provenance() {
+		provenance[key] = info.String()
+	}
+
+	status := c.metadata()
+	if err := c.stats.snapshot().reloadErr; err != nil {
+		status["error"] = err.Error()
+	}
+
+	history := []adminSnapshot{}
+	for _, snapshot := range c.History() {
+		history = append(history, adminSnapshot{
+			Version:  snapshot.Version,
+			Time:     formatTime(snapshot.Time),
+			Checksum: snapshot.Checksum,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	_ = encoder.Encode(map[string]any{
+		"config":     settings,
+		"provenance": provenance,
+		"status":     status,
+		"history":    history,
+	})
+}
+
+// reloadResult is the response of the reload endpoint.
+type reloadResult struct {
+	Success bool     `json:"success"`
+	Error   string   `json:"error,omitempty"`
+	Changes []string `json:"changes,omitempty"`
+}
+
+// ReloadHandler returns an http.Handler which reloads the config like Reload,
+// e.g. after new config files were deployed. It responds with the result and
+// the changed values as JSON, 200 OK if the reload succeeded and 422
+// Unprocessable Entity otherwise. With WithAdminToken requests without the
+// bearer token are rejected with 401 Unauthorized.
+func (c *loader[T]) ReloadHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		if !c.authorized(r) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+
+			return
+		}
+
+		result := reloadResult{Success: true}
+		status := http.StatusOK
+
+		if err := c.reload(triggerHTTP); err != nil {
+			result = reloadResult{Error: err.Error()}
+			status = http.StatusUnprocessableEntity
+		} else {
+			for _, change := range c.LastDiff() {
+				result.Changes = append(result.Changes, change.String())
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+
+		_ = encoder.Encode(result)
+	})
+}
+
+// authorized reports whether the request has the bearer token of
+// WithAdminToken, all requests are authorized without a token.
+func (c *loader[T]) authorized(r *http.Request) bool {
+	if c.adminToken == "" {
+		return true
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(c.adminToken)) == 1
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// ageHeader starts the binary age format, armored files start with the PEM
+// header of armor.
+var ageHeader = []byte("age-encryption.org/")
+
+// WithAgeDecryption is an option to decrypt age encrypted config files,
+// readers and sources before parsing, in the binary or the armored format. The
+// format of a file like config.yml.age is derived from the extension before
+// ".age". Unencrypted data is read as is.
+func WithAgeDecryption[T any](identities ...age.Identity) Option[T] {
+	return func(cl *loader[T]) {
+		cl.ageIdentities = append(cl.ageIdentities, identities...)
+
+		// files given by earlier options are read again decrypted
+		if cl.hasConfigFiles() {
+			if err := cl.readConfig(); err != nil {
+				cl.logger.Error("Failed to read config from file", "error", err)
+			}
+		}
+	}
+}
+
+// WithAgeIdentityFile is an option to decrypt age encrypted configs like
+// WithAgeDecryption with the identities of a key file, e.g. the file of
+// age-keygen. Use os.Getenv to take the path from the environment.
+func WithAgeIdentityFile[T any](path string) Option[T] {
+	return func(cl *loader[T]) {
+		identities, err := readAgeIdentities(path)
+		if err != nil {
+			cl.logger.Error("Failed to read age identities", "path", path, "error", err)
+
+			return
+		}
+
+		WithAgeDecryption[T](identities...)(cl)
+	}
+}
+
+// readAgeIdentities parses the identities of the key file.
+func readAgeIdentities(path string) ([]age.Identity, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return age.ParseIdentities(f)
+}
+
+// decryptAge decrypts the data if it is age encrypted.
+func decryptAge(data []byte, identities []age.Identity) ([]byte, error) {
+	var reader io.Reader
+
+	switch trimmed := bytes.TrimSpace(data); {
+	case bytes.HasPrefix(trimmed, []byte(armor.Header)):
+		reader = armor.NewReader(bytes.NewReader(trimmed))
+	case bytes.HasPrefix(data, ageHeader):
+		reader = bytes.NewReader(data)
+	default:
+		return data, nil
+	}
+
+	decrypted, err := age.Decrypt(reader, identities...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt age data: %w", err)
+	}
+
+	return io.ReadAll(decrypted)
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// WithAlias is an option to keep an old key working after a rename, the value
+// of the alias is used for the key unless the key itself is set. Nested keys
+// are separated by "." or "_", e.g. WithAlias("db.host", "databaseConfig.host").
+// Aliases of sections apply to all nested keys. The provenance of the key
+// names the alias.
+func WithAlias[T any](alias, key string) Option[T] {
+	return func(cl *loader[T]) {
+		if cl.aliases == nil {
+			cl.aliases = make(map[string]string)
+		}
+
+		cl.aliases[aliasKey(alias)] = aliasKey(key)
+	}
+}
+
+// aliasKey returns the key in the format of viper.
+func aliasKey(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, ".", keyDelimiter))
+}
+
+// applyAliases merges the values of the aliases set by config files or sources
+// into the config at their keys.
+func (c *loader[T]) applyAliases() error {
+	origins := slices.Sorted(maps.Keys(c.origins))
+
+	for _, alias := range slices.Sorted(maps.Keys(c.aliases)) {
+		settings := make(map[string]any)
+
+		for _, origin := range origins {
+			if origin != alias && !strings.HasPrefix(origin, alias+keyDelimiter) {
+				continue
+			}
+
+			key := c.aliases[alias] + strings.TrimPrefix(origin, alias)
+			if _, ok := c.origins[key]; ok {
+				continue
+			}
+
+			info := c.origins[origin]
+			info.Alias = origin
+
+			value := c.viper.Get(origin)
+			setPath(settings, strings.Split(key, keyDelimiter), value)
+			c.setOrigin(key, value, info)
+		}
+
+		if err := c.viper.MergeConfigMap(settings); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+)
+
+// The triggers of reloads.
+const (
+	triggerFile   = "file"   // a config file changed
+	triggerSignal = "signal" // a reload signal was received
+	triggerSource = "source" // a source reported a change
+	triggerManual = "manual" // Reload was called
+	triggerHTTP   = "http"   // the reload endpoint was called
+
+	triggerRollback = "rollback" // not a reload, the config was rolled back
+	triggerSet      = "set"      // not a reload, a value was set at runtime
+	triggerPatch    = "patch"    // not a reload, a patch was applied at runtime
+)
+
+// auditEntry is a line of the audit log.
+type auditEntry struct {
+	Time     time.Time `json:"time"`
+	Trigger  string    `json:"trigger"`
+	Success  bool      `json:"success"`
+	Error    string    `json:"error,omitempty"`
+	Changes  []string  `json:"changes,omitempty"`
+	Checksum string    `json:"checksum,omitempty"`
+}
+
+// WithAuditLog is an option to append every reload to an audit log as a line
+// of JSON with the time, the trigger ("file", "signal", "source", "manual" or
+// "http"), the result, the changed values and the SHA-256 checksum of the effective config.
+// The values of secret fields are masked.
+//
+//	{"time":"2025-01-31T12:00:00Z","trigger":"file","success":true,"changes":["port: 5432 -> 6543"],"checksum":"9f86..."}
+func WithAuditLog[T any](w io.Writer) Option[T] {
+	return func(cl *loader[T]) {
+		cl.auditLog = w
+	}
+}
+
+// WithAuditFile is an option to append the audit log of WithAuditLog to the
+// file, which is created if it does not exist.
+func WithAuditFile[T any](path string) Option[T] {
+	return func(cl *loader[T]) {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			cl.logger.Error("Failed to open audit log", "path", path, "error", err)
+
+			return
+		}
+
+		cl.auditLog = f
+	}
+}
+
+// audit appends the result of a reload to the audit log.
+func (c *loader[T]) audit(trigger string, err error, diff []Change, settings map[string]any) {
+	if c.auditLog == nil {
+		return
+	}
+
+	entry := auditEntry{
+		Time:    time.Now().UTC(),
+		Trigger: trigger,
+		Success: err == nil,
+	}
+
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	for _, change := range diff {
+		entry.Changes = append(entry.Changes, change.String())
+	}
+
+	if settings != nil {
+		entry.Checksum = checksum(settings)
+	}
+
+	line, _ := json.Marshal(entry)
+
+	c.auditMu.Lock()
+	defer c.auditMu.Unlock()
+
+	if _, err := c.auditLog.Write(append(line, '\n')); err != nil {
+		c.logger.Error("Failed to write audit log", "error", err)
+	}
+}
+
+// checksum returns the hex encoded SHA-256 checksum of the settings, maps are
+// encoded with sorted keys so the checksum is stable.
+func checksum(settings map[string]any) string {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,203 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"schneider.vip/config/internal/azure"
+)
+
+const (
+	azureAppConfigAPIVersion = "1.0"
+	azureKeyVaultAPIVersion  = "7.4"
+	azureKeyVaultScope       = "https://vault.azure.net/.default"
+	azureKeyVaultRefType     = "application/vnd.microsoft.appconfig.keyvaultref+json"
+)
+
+// WithAzureAppConfig is an option to load the key-values of an Azure App
+// Configuration store with the given label, an empty label selects the
+// key-values without label. Keys are split into sections at ":" and "/",
+// e.g. "databaseConfig:host". Key Vault references are resolved to the secret
+// values. StartWatcher polls the store in the poll interval.
+// The endpoint is either the store URL, authenticated with the default Azure
+// credentials, or a connection string of an access key.
+func WithAzureAppConfig[T any](endpoint, label string, opts ...RemoteOption) Option[T] {
+	return func(cl *loader[T]) {
+		o := newRemoteOptions("", opts)
+		s := &azureAppConfigSource{
+			label:  label,
+			opts:   o,
+			tokens: azure.NewTokenProvider(o.httpClient),
+		}
+
+		if strings.Contains(endpoint, "Endpoint=") {
+			for _, part := range strings.Split(endpoint, ";") {
+				key, value, _ := strings.Cut(part, "=")
+
+				switch key {
+				case "Endpoint":
+					s.endpoint = value
+				case "Id":
+					s.credential = value
+				case "Secret":
+					s.secret = value
+				}
+			}
+		} else {
+			s.endpoint = endpoint
+		}
+
+		s.endpoint = strings.TrimSuffix(s.endpoint, "/")
+		cl.addSource(s)
+	}
+}
+
+// azureAppConfigSource reads the key-values of an Azure App Configuration store.
+type azureAppConfigSource struct {
+	endpoint   string
+	credential string // access key id of a connection string
+	secret     string // access key secret of a connection string
+	label      string
+	opts       remoteOptions
+	tokens     *azure.TokenProvider
+}
+
+type azureKeyValue struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	ContentType string `json:"content_type"`
+}
+
+// Read returns the key-values as JSON.
+func (s *azureAppConfigSource) Read() ([]byte, string, error) {
+	ctx, cancel := s.opts.readContext()
+	defer cancel()
+
+	label := s.label
+	if label == "" {
+		label = "\x00"
+	}
+
+	query := url.Values{"label": {label}, "api-version": {azureAppConfigAPIVersion}}
+	next := "/kv?" + query.Encode()
+	settings := make(map[string]any)
+
+	for next != "" {
+		var response struct {
+			Items    []azureKeyValue `json:"items"`
+			NextLink string          `json:"@nextLink"`
+		}
+
+		if err := s.get(ctx, s.endpoint+next, &response); err != nil {
+			return nil, "", fmt.Errorf("failed to read azure app config: %w", err)
+		}
+
+		for _, item := range response.Items {
+			value, err := s.resolve(ctx, item)
+			if err != nil {
+				return nil, "", err
+			}
+
+			keys := strings.FieldsFunc(strings.ToLower(item.Key), func(r rune) bool { return r == ':' || r == '/' })
+			if len(keys) > 0 {
+				setPath(settings, keys, value)
+			}
+		}
+
+		next = response.NextLink
+	}
+
+	data, err := json.Marshal(nestSection(s.opts.section, settings))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, "json", nil
+}
+
+// Watch polls the store for changes.
+func (s *azureAppConfigSource) Watch(ctx context.Context) <-chan struct{} {
+	return pollChanges(ctx, s.opts.pollInterval, func() ([]byte, error) {
+		data, _, err := s.Read()
+
+		return data, err
+	})
+}
+
+// resolve returns the value of the key-value, Key Vault references are
+// resolved to the secret value.
+func (s *azureAppConfigSource) resolve(ctx context.Context, item azureKeyValue) (string, error) {
+	if !strings.HasPrefix(item.ContentType, azureKeyVaultRefType) {
+		return item.Value, nil
+	}
+
+	var reference struct {
+		URI string `json:"uri"`
+	}
+
+	if err := json.Unmarshal([]byte(item.Value), &reference); err != nil {
+		return "", fmt.Errorf("invalid key vault reference %s: %w", item.Key, err)
+	}
+
+	token, err := s.tokens.Token(ctx, azureKeyVaultScope)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reference.URI+"?api-version="+azureKeyVaultAPIVersion, nil)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := doRequest(s.opts.httpClient, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve key vault reference %s: %w", item.Key, err)
+	}
+	defer resp.Body.Close()
+
+	var secret struct {
+		Value string `json:"value"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return "", fmt.Errorf("failed to decode key vault secret %s: %w", item.Key, err)
+	}
+
+	return secret.Value, nil
+}
+
+// get requests the URL of the store and decodes the JSON response.
+func (s *azureAppConfigSource) get(ctx context.Context, url string, response any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	if s.secret != "" {
+		if err := azure.SignHMAC(req, s.credential, s.secret, time.Now()); err != nil {
+			return err
+		}
+	} else {
+		token, err := s.tokens.Token(ctx, s.endpoint+"/.default")
+		if err != nil {
+			return err
+		}
+
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := doRequest(s.opts.httpClient, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return json.NewDecoder(resp.Body).Decode(response)
+}
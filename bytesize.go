@@ -0,0 +1,123 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a size in bytes, decoded from human-readable sizes like
+// "512MiB" or "2GB". Plain numbers are bytes.
+type ByteSize int64
+
+// Decimal and binary byte size units.
+const (
+	Byte ByteSize = 1
+	KB   ByteSize = 1000
+	MB            = 1000 * KB
+	GB            = 1000 * MB
+	TB            = 1000 * GB
+	PB            = 1000 * TB
+	EB            = 1000 * PB
+	KiB  ByteSize = 1 << 10
+	MiB           = 1 << 10 * KiB
+	GiB           = 1 << 10 * MiB
+	TiB           = 1 << 10 * GiB
+	PiB           = 1 << 10 * TiB
+	EiB           = 1 << 10 * PiB
+)
+
+// byteSizeUnits are the units of ParseByteSize, by lower-cased suffix.
+var byteSizeUnits = map[string]ByteSize{
+	"": Byte, "b": Byte,
+	"k": KB, "kb": KB, "kib": KiB,
+	"m": MB, "mb": MB, "mib": MiB,
+	"g": GB, "gb": GB, "gib": GiB,
+	"t": TB, "tb": TB, "tib": TiB,
+	"p": PB, "pb": PB, "pib": PiB,
+	"e": EB, "eb": EB, "eib": EiB,
+}
+
+var byteSizeType = reflect.TypeOf(ByteSize(0))
+
+// ParseByteSize parses a human-readable size like "512MiB", "1.5 GB" or
+// "1024". Units are case-insensitive, KB, MB, ... are decimal and KiB, MiB,
+// ... binary units.
+func ParseByteSize(s string) (ByteSize, error) {
+	value := strings.TrimSpace(s)
+	number := strings.TrimRightFunc(value, func(r rune) bool {
+		return r < '0' || r > '9'
+	})
+	unit := strings.ToLower(strings.TrimSpace(value[len(number):]))
+
+	size, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid byte size %q, expected a size like \"512MiB\" or \"2GB\"", s)
+	}
+
+	multiplier, ok := byteSizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid byte size %q, unknown unit %q", s, unit)
+	}
+
+	// whole numbers are exact, floats lose precision above 2^53
+	if n, err := strconv.ParseInt(strings.TrimSpace(number), 10, 64); err == nil && n <= math.MaxInt64/int64(multiplier) {
+		return ByteSize(n) * multiplier, nil
+	}
+
+	// float64(1<<63) is the first value beyond the maximum, math.MaxInt64
+	// rounds up to it
+	bytes := size * float64(multiplier)
+	if bytes >= 1<<63 {
+		return 0, fmt.Errorf("invalid byte size %q, exceeds the maximum", s)
+	}
+
+	return ByteSize(bytes), nil
+}
+
+// String returns the size in the largest unit without fraction, e.g. "512MiB"
+// or "2GB".
+func (b ByteSize) String() string {
+	for _, unit := range []struct {
+		name string
+		size ByteSize
+	}{
+		{"EiB", EiB}, {"EB", EB}, {"PiB", PiB}, {"PB", PB}, {"TiB", TiB}, {"TB", TB}, {"GiB", GiB}, {"GB", GB},
+		{"MiB", MiB}, {"MB", MB}, {"KiB", KiB}, {"KB", KB},
+	} {
+		if b != 0 && b%unit.size == 0 {
+			return strconv.FormatInt(int64(b/unit.size), 10) + unit.name
+		}
+	}
+
+	return strconv.FormatInt(int64(b), 10) + "B"
+}
+
+// UnmarshalText parses the size like ParseByteSize.
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	size, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+
+	*b = size
+
+	return nil
+}
+
+// MarshalText returns the size like String.
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// byteSizeHook decodes strings like "512MiB" into ByteSize fields.
+func byteSizeHook(_ reflect.Type, to reflect.Type, data any) (any, error) {
+	value, ok := data.(string)
+	if !ok || to != byteSizeType {
+		return data, nil
+	}
+
+	return ParseByteSize(value)
+}
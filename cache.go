@@ -0,0 +1,174 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// WithSourceCache is an option to cache the data of the sources in dir. After
+// every successful read the data is written to the cache, if a source is
+// unreachable, e.g. at startup during an outage of the config backend, the
+// cached data is used instead. Only network errors and timeouts fall back to
+// the cache, errors like a denied access or a missing key are returned. The
+// cache files are named by the type and the address of the source, so adding
+// or removing sources keeps the cache of the others; custom sources are
+// identified by their String method if they implement fmt.Stringer, else by
+// their position. Cache files may contain secrets and are only readable by the
+// owner.
+func WithSourceCache[T any](dir string) Option[T] {
+	return func(cl *loader[T]) {
+		cl.sourceCacheDir = dir
+	}
+}
+
+// cachedSource is the cache file content of a source.
+type cachedSource struct {
+	Format string `json:"format"`
+	Data   []byte `json:"data"`
+}
+
+// readSource reads the source with index i, falling back to the cache.
+func (c *loader[T]) readSource(i int, s Source) ([]byte, string, error) {
+	data, configType, err := c.readWithRetry(s)
+	c.stats.recordSource(sourceName(i, s), err)
+
+	if c.sourceCacheDir == "" {
+		return data, configType, err
+	}
+
+	path := filepath.Join(c.sourceCacheDir, "source-"+sourceCacheKey(i, s)+".json")
+
+	if err != nil {
+		if !isTransportError(err) {
+			return nil, "", err
+		}
+
+		cached, cacheErr := os.ReadFile(path)
+		if cacheErr != nil {
+			return nil, "", err
+		}
+
+		var entry cachedSource
+		if cacheErr := json.Unmarshal(cached, &entry); cacheErr != nil {
+			return nil, "", err
+		}
+
+		c.logger.Error("Failed to read config from source, using cached config", "cache", path, "error", err)
+
+		return entry.Data, entry.Format, nil
+	}
+
+	if err := writeSourceCache(path, cachedSource{Format: configType, Data: data}); err != nil {
+		c.logger.Error("Failed to write source cache", "cache", path, "error", err)
+	}
+
+	return data, configType, nil
+}
+
+// writeSourceCache writes the cache file atomically.
+func writeSourceCache(path string, entry cachedSource) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// sourceCacheKey returns the hash of the type and the identity of the source.
+func sourceCacheKey(i int, s Source) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%T %s", s, sourceIdentity(i, s))))
+
+	return hex.EncodeToString(sum[:16])
+}
+
+// sourceIdentity returns the address of the data of the source, e.g. the
+// endpoints and the key in etcd, or the position of unknown sources.
+func sourceIdentity(i int, s Source) string {
+	switch s := s.(type) {
+	case *etcdSource:
+		return strings.Join(s.endpoints, ",") + " " + s.key
+	case *consulSource:
+		return s.address + " " + s.key
+	case *vaultSource:
+		return s.address + " " + s.path
+	case *ssmSource:
+		return s.opts.region + " " + s.opts.endpoint + " " + s.path
+	case *secretsManagerSource:
+		return s.opts.region + " " + s.opts.endpoint + " " + s.secretID
+	case *gcpSecretManagerSource:
+		names := make([]string, 0, len(s.secrets))
+		for key, name := range s.secrets {
+			names = append(names, key+"="+name)
+		}
+
+		slices.Sort(names)
+
+		return strings.Join(names, ",")
+	case *azureAppConfigSource:
+		return s.endpoint + " " + s.label
+	case *kubernetesSource:
+		return s.resource + " " + s.namespace + "/" + s.name + " " + s.key
+	case *urlSource:
+		return s.url
+	case *redisSource:
+		return s.address + " " + strconv.Itoa(s.db) + " " + s.key
+	case *natsKVSource:
+		return s.address + " " + s.bucket + " " + s.key
+	case *gitSource:
+		return s.url + " " + s.ref + " " + s.path
+	case *sqlSource:
+		return s.query
+	case *zkSource:
+		return strings.Join(s.servers, ",") + " " + s.path
+	case *grpcSource:
+		return s.service
+	case *secretsPlatformSource:
+		return s.name
+	case *commandSource:
+		return s.command
+	case fmt.Stringer:
+		return s.String()
+	}
+
+	return strconv.Itoa(i)
+}
+
+// isTransportError reports whether the error is a network error or a timeout,
+// i.e. the source is unreachable.
+func isTransportError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"context"
+	"slices"
+
+	"github.com/urfave/cli/v2"
+)
+
+// WithCLIContext is an option to override the config with the flags of the
+// urfave/cli context and its parents, which were set on the command line or by
+// their environment variables. Flags bind to keys like for WithFlagSet by their
+// first name.
+func WithCLIContext[T any](cCtx *cli.Context) Option[T] {
+	return func(cl *loader[T]) {
+		cl.cliContexts = append(cl.cliContexts, cCtx)
+	}
+}
+
+// BindCLI returns a Before function of a urfave/cli app or command, which loads
+// the config with the flags of the context like for WithCLIContext. A "config"
+// flag is the config file. The loader is stored in the context, get it in the
+// action with FromContext(cCtx.Context), a failed parse is returned as error.
+func BindCLI[T any](opts ...Option[T]) cli.BeforeFunc {
+	return func(cCtx *cli.Context) error {
+		opts := slices.Clone(opts)
+		if cCtx.IsSet(configFlag) {
+			opts = append(opts, WithConfigFile[T](cCtx.String(configFlag)))
+		}
+
+		loader := New(append(opts, WithCLIContext[T](cCtx), DisableAutoParse[T]())...)
+		if err := loader.Parse(); err != nil {
+			return err
+		}
+
+		ctx := cCtx.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		cCtx.Context = context.WithValue(ctx, contextKey[T]{}, loader)
+
+		return nil
+	}
+}
+
+// bindCLIFlags overrides the keys of the flags, which are set in the cli
+// contexts, keys are the keys of the fields by flag name.
+func (c *loader[T]) bindCLIFlags(keys map[string]string) {
+	c.cliFlags = make(map[string]string)
+
+	for _, cCtx := range c.cliContexts {
+		for _, lineage := range cCtx.Lineage() {
+			if lineage.Command == nil {
+				continue
+			}
+
+			for _, flag := range lineage.Command.Flags {
+				name := flag.Names()[0]
+				if name == configFlag || !cCtx.IsSet(name) {
+					continue
+				}
+
+				key, ok := keys[name]
+				if !ok {
+					key = flagKey(c.subSection, name)
+				}
+
+				c.viper.Set(key, cCtx.Value(name))
+				c.cliFlags[key] = "--" + name
+			}
+		}
+	}
+}
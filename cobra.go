@@ -0,0 +1,60 @@
+package config
+
+import (
+	"context"
+	"slices"
+
+	"github.com/spf13/cobra"
+)
+
+// configFlag is the flag of the config file of BindCobra.
+const configFlag = "config"
+
+// contextKey is the key of the loader in the context of a command.
+type contextKey[T any] struct{}
+
+// BindCobra wires the config into cmd and its subcommands. It adds the
+// persistent --config flag of the config file and loads the config before the
+// command runs, with the flags of the command bound like for WithFlagSet. The
+// loader is stored in the context of the command, get it in PreRunE, RunE and
+// PostRunE with FromContext. A PersistentPreRun(E) of cmd runs after the config
+// was loaded, a failed parse is returned as error of the command.
+func BindCobra[T any](cmd *cobra.Command, opts ...Option[T]) {
+	if cmd.PersistentFlags().Lookup(configFlag) == nil {
+		cmd.PersistentFlags().String(configFlag, "", "config file")
+	}
+
+	preRunE, preRun := cmd.PersistentPreRunE, cmd.PersistentPreRun
+	cmd.PersistentPreRun = nil
+
+	cmd.PersistentPreRunE = func(c *cobra.Command, args []string) error {
+		opts := slices.Clone(opts)
+		if configFile, _ := c.Flags().GetString(configFlag); configFile != "" {
+			opts = append(opts, WithConfigFile[T](configFile))
+		}
+
+		loader := New(append(opts, WithFlagSet[T](c.Flags()), DisableAutoParse[T]())...)
+		if err := loader.Parse(); err != nil {
+			return err
+		}
+
+		c.SetContext(context.WithValue(c.Context(), contextKey[T]{}, loader))
+
+		switch {
+		case preRunE != nil:
+			return preRunE(c, args)
+		case preRun != nil:
+			preRun(c, args)
+		}
+
+		return nil
+	}
+}
+
+// FromContext returns the loader of BindCobra, which is stored in the context
+// of a command, nil if there is none.
+func FromContext[T any](ctx context.Context) Manager[T] {
+	loader, _ := ctx.Value(contextKey[T]{}).(Manager[T])
+
+	return loader
+}
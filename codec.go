@@ -0,0 +1,501 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/hcl"
+	"github.com/magiconair/properties"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"gopkg.in/ini.v1"
+	"gopkg.in/yaml.v3"
+)
+
+// Codec decodes and encodes a config format, see RegisterCodec.
+type Codec interface {
+	// Decode decodes the data into nested maps of settings.
+	Decode(data []byte) (map[string]any, error)
+	// Encode encodes the nested maps of settings.
+	Encode(settings map[string]any) ([]byte, error)
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = make(map[string]Codec)
+)
+
+// RegisterCodec registers a codec for the config type name, e.g. "msgpack".
+// The name is also the file extension of the format, e.g. for config.msgpack
+// and the files of WithConfigDir. A codec overrides the built-in format of the
+// name. RegisterCodec is usually called in an init function.
+func RegisterCodec(name string, codec Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+
+	codecs[name] = codec
+}
+
+// hasCodec reports whether a codec is registered for the config type.
+func hasCodec(configType string) bool {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+
+	_, ok := codecs[configType]
+
+	return ok
+}
+
+// decoder returns the decoder of the config type, registered codecs take
+// precedence over the built-in decoders. Returns false if viper decodes the
+// config type.
+func decoder(configType string) (func(data []byte) (map[string]any, error), bool) {
+	codecsMu.RLock()
+	codec, ok := codecs[configType]
+	codecsMu.RUnlock()
+
+	if ok {
+		return codec.Decode, true
+	}
+
+	decode, ok := decoders[configType]
+
+	return decode, ok
+}
+
+var errUnsupportedFormat = errors.New("unsupported config format")
+
+// encodeConfig encodes the settings in the format of the config type, by a
+// registered codec or like viper writes config files.
+func encodeConfig(settings map[string]any, configType string) ([]byte, error) {
+	codecsMu.RLock()
+	codec, ok := codecs[configType]
+	codecsMu.RUnlock()
+
+	if ok {
+		return codec.Encode(settings)
+	}
+
+	if !slices.Contains(viper.SupportedExts, configType) {
+		return nil, fmt.Errorf("%w: %q", errUnsupportedFormat, configType)
+	}
+
+	v := viper.NewWithOptions(viper.KeyDelimiter(keyDelimiter))
+	fs := afero.NewMemMapFs()
+	v.SetFs(fs)
+
+	if err := v.MergeConfigMap(settings); err != nil {
+		return nil, err
+	}
+
+	filename := "config." + configType
+	if err := v.WriteConfigAs(filename); err != nil {
+		return nil, err
+	}
+
+	return afero.ReadFile(fs, filename)
+}
+
+// isConfigExt reports whether files with the extension are loaded from a
+// config directory.
+func isConfigExt(ext string) bool {
+	return slices.Contains(configDirExts, ext) || hasCodec(strings.TrimPrefix(ext, "."))
+}
+
+// decoders decode the config formats which viper does not support or whose
+// result needs to be normalized, by config type.
+var decoders = map[string]func(data []byte) (map[string]any, error){
+	"yaml":       decodeYAML,
+	"yml":        decodeYAML,
+	"hcl":        decodeHCL,
+	"tfvars":     decodeHCL,
+	"ini":        decodeINI,
+	"properties": decodeProperties,
+	"props":      decodeProperties,
+	"prop":       decodeProperties,
+	"jsonc":      decodeJSONC,
+	"cue":        decodeCUE,
+	"jsonnet":    decodeJsonnet,
+	"libsonnet":  decodeJsonnet,
+	"textproto":  decodeTextproto,
+	"txtpb":      decodeTextproto,
+	"pbtxt":      decodeTextproto,
+}
+
+// fileType returns the config type of the file, set by WithConfigType or
+// derived from the extension.
+func (c *loader[T]) fileType(path string) string {
+	if c.configType != "" {
+		return c.configType
+	}
+
+	return strings.TrimPrefix(configExt(path), ".")
+}
+
+// configExt returns the extension of the config file, the extension before
+// ".age" for age encrypted files like config.yml.age.
+func configExt(path string) string {
+	return filepath.Ext(strings.TrimSuffix(path, ".age"))
+}
+
+// readInConfig reads the config file of viper like viper.ReadInConfig, with
+// the formats of the decoders.
+func (c *loader[T]) readInConfig() error {
+	path := c.viper.ConfigFileUsed()
+
+	data, err := c.readFile(path)
+	if err != nil {
+		return err
+	}
+
+	return c.replaceConfig(data, c.fileType(path), path)
+}
+
+// readFile reads the config file, checks its permissions and verifies its
+// signature.
+func (c *loader[T]) readFile(path string) ([]byte, error) {
+	if err := c.checkPermissions(path); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.verifySignature(path, data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// checksFiles reports whether the permissions or signatures of the config
+// files are checked and may reject them.
+func (c *loader[T]) checksFiles() bool {
+	return (c.permissionCheck && !c.permissionWarning) || c.signatureVerifier != nil
+}
+
+// checkFiles checks all config files like readFile, so a config with a
+// rejected file is not loaded even though the failed read was only logged.
+func (c *loader[T]) checkFiles() error {
+	if !c.checksFiles() {
+		return nil
+	}
+
+	for _, path := range c.watchedFiles() {
+		if path == "" {
+			continue
+		}
+
+		// only a missing config file is skipped, not a missing signature
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		if _, err := c.readFile(path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readConfigData reads the config data of WithConfigReader like
+// viper.ReadConfig, with the formats of the decoders.
+func (c *loader[T]) readConfigData(data []byte) error {
+	return c.replaceConfig(data, c.configType, "")
+}
+
+// replaceConfig replaces the config with the decoded data of the file, or of
+// a reader if filename is empty.
+func (c *loader[T]) replaceConfig(data []byte, configType, filename string) error {
+	settings, err := c.decodeConfig(data, configType, filename)
+	if err != nil {
+		return err
+	}
+
+	// ReadConfig replaces the config before decoding, the result of the empty
+	// input is irrelevant
+	_ = c.viper.ReadConfig(strings.NewReader(""))
+
+	c.resetOrigins()
+	if filename != "" {
+		c.setOrigins(settings, SourceInfo{Kind: KindFile, Name: filename})
+	} else {
+		c.setOrigins(settings, SourceInfo{Kind: KindReader})
+	}
+
+	return c.viper.MergeConfigMap(settings)
+}
+
+// mergeInConfig merges the config file of viper like viper.MergeInConfig,
+// with the formats of the decoders.
+func (c *loader[T]) mergeInConfig() error {
+	return c.mergeFile(c.viper.ConfigFileUsed())
+}
+
+// mergeFile merges the config file into the config.
+func (c *loader[T]) mergeFile(path string) error {
+	data, err := c.readFile(path)
+	if err != nil {
+		return err
+	}
+
+	settings, err := c.decodeConfig(data, c.fileType(path), path)
+	if err != nil {
+		return err
+	}
+
+	c.setOrigins(settings, SourceInfo{Kind: KindFile, Name: path})
+
+	return c.viper.MergeConfigMap(settings)
+}
+
+// decodeConfig decodes the data of the file like decodeConfig. Age encrypted
+// data and the data of the decrypters of WithDecrypter is decrypted, Jsonnet is evaluated with the options of the
+// loader and the protobuf text format is decoded into the config type.
+func (c *loader[T]) decodeConfig(data []byte, configType, filename string) (map[string]any, error) {
+	if len(c.ageIdentities) > 0 {
+		decrypted, err := decryptAge(data, c.ageIdentities)
+		if err != nil {
+			return nil, err
+		}
+
+		data = decrypted
+	}
+
+	for _, decrypter := range c.decrypters {
+		decrypted, err := decrypter.Decrypt(data, configType)
+		if err != nil {
+			return nil, err
+		}
+
+		data = decrypted
+	}
+
+	if hasCodec(configType) {
+		return decodeConfig(data, configType)
+	}
+
+	switch configType {
+	case "jsonnet", "libsonnet":
+		return c.evaluateJsonnet(filename, data)
+	case "textproto", "txtpb", "pbtxt":
+		return c.decodeTextproto(data)
+	}
+
+	return decodeConfig(data, configType)
+}
+
+// decodeYAML decodes YAML, the documents of a multi-document file are
+// deep-merged in order.
+func decodeYAML(data []byte) (map[string]any, error) {
+	settings := make(map[string]any)
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+
+	for {
+		var document map[string]any
+		if err := decoder.Decode(&document); errors.Is(err, io.EOF) {
+			return settings, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		mergeSettings(settings, document)
+	}
+}
+
+// mergeSettings deep-merges src into dst, keys are compared case-insensitively
+// like viper does.
+func mergeSettings(dst, src map[string]any) {
+	for key, value := range src {
+		key = strings.ToLower(key)
+
+		nested, ok := value.(map[string]any)
+		if existing, isMap := dst[key].(map[string]any); ok && isMap {
+			mergeSettings(existing, nested)
+
+			continue
+		}
+
+		if ok {
+			merged := make(map[string]any, len(nested))
+			mergeSettings(merged, nested)
+			value = merged
+		}
+
+		dst[key] = value
+	}
+}
+
+// decodeHCL decodes HCL. Blocks are decoded by HCL as lists of objects, they
+// are normalized to objects: a block becomes an object and the blocks of a
+// name with distinct labels are merged into one object of the labels.
+func decodeHCL(data []byte) (map[string]any, error) {
+	var settings map[string]any
+	if err := hcl.Unmarshal(data, &settings); err != nil {
+		return nil, err
+	}
+
+	normalizeHCL(settings)
+
+	return settings, nil
+}
+
+// normalizeHCL replaces the lists of blocks in settings with objects. A list
+// of objects with overlapping keys, e.g. [{name: a}, {name: b}], is kept.
+func normalizeHCL(settings map[string]any) {
+	for key, value := range settings {
+		blocks, ok := value.([]map[string]any)
+		if !ok {
+			continue
+		}
+
+		merged := make(map[string]any)
+
+		for _, block := range blocks {
+			for name, nested := range block {
+				if _, exists := merged[name]; exists {
+					merged = nil
+
+					break
+				}
+
+				merged[name] = nested
+			}
+
+			if merged == nil {
+				break
+			}
+		}
+
+		if merged == nil {
+			for _, block := range blocks {
+				normalizeHCL(block)
+			}
+
+			continue
+		}
+
+		normalizeHCL(merged)
+		settings[key] = merged
+	}
+}
+
+// decodeINI decodes INI. The keys of the default section are top-level keys,
+// sections map to nested objects and section names like "database.replica"
+// are split at "." into nested objects.
+func decodeINI(data []byte) (map[string]any, error) {
+	file, err := ini.Load(data)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make(map[string]any)
+
+	for _, section := range file.Sections() {
+		var path []string
+		if section.Name() != ini.DefaultSection {
+			path = strings.Split(section.Name(), ".")
+		}
+
+		for _, key := range section.Keys() {
+			setPath(settings, append(slices.Clone(path), key.Name()), key.String())
+		}
+	}
+
+	return settings, nil
+}
+
+// decodeProperties decodes Java properties, dotted keys like "database.host"
+// map to nested objects.
+func decodeProperties(data []byte) (map[string]any, error) {
+	props, err := properties.Load(data, properties.UTF8)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make(map[string]any)
+
+	for _, key := range props.Keys() {
+		value, _ := props.Get(key)
+		setPath(settings, strings.Split(key, "."), value)
+	}
+
+	return settings, nil
+}
+
+// decodeJSONC decodes JSON with // and /* */ comments and trailing commas.
+func decodeJSONC(data []byte) (map[string]any, error) {
+	settings := make(map[string]any)
+	if err := json.Unmarshal(stripJSONC(data), &settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// stripJSONC removes comments and trailing commas outside of strings, comments
+// are replaced by spaces to keep the offsets of syntax errors.
+func stripJSONC(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	comma := -1
+
+	for i := 0; i < len(data); i++ {
+		switch ch := data[i]; {
+		case ch == '"':
+			start := i
+			for i++; i < len(data) && data[i] != '"'; i++ {
+				if data[i] == '\\' {
+					i++
+				}
+			}
+			out = append(out, data[start:min(i+1, len(data))]...)
+			comma = -1
+		case ch == '/' && i+1 < len(data) && data[i+1] == '/':
+			for ; i < len(data) && data[i] != '\n'; i++ {
+				out = append(out, ' ')
+			}
+			i--
+		case ch == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				end = len(data) - i - 2
+			} else {
+				end += 2
+			}
+			for _, c := range data[i : i+2+end] {
+				if c == '\n' {
+					out = append(out, c)
+				} else {
+					out = append(out, ' ')
+				}
+			}
+			i += 1 + end
+		case ch == ',':
+			comma = len(out)
+			out = append(out, ch)
+		case (ch == '}' || ch == ']') && comma >= 0:
+			out[comma] = ' '
+			comma = -1
+			out = append(out, ch)
+		default:
+			if ch != ' ' && ch != '\t' && ch != '\r' && ch != '\n' {
+				comma = -1
+			}
+			out = append(out, ch)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// WithCommandSource is an option to load the config from the standard output
+// of a shell command, e.g. "vault kv get -format=json secret/myapp", in the
+// given format. The command runs on load and, only if WithPollInterval is set,
+// again in the poll interval while StartWatcher reloads if the output changed.
+// The command is killed after the timeout of WithTimeout, 10 seconds by
+// default.
+func WithCommandSource[T any](command, format string, opts ...RemoteOption) Option[T] {
+	return func(cl *loader[T]) {
+		var explicit remoteOptions
+		for _, opt := range opts {
+			opt(&explicit)
+		}
+
+		o := newRemoteOptions("", opts)
+		o.configType = format
+
+		cl.addSource(&commandSource{
+			command: command,
+			opts:    o,
+			rerun:   explicit.pollInterval > 0,
+		})
+	}
+}
+
+// commandSource reads the config from the output of a command.
+type commandSource struct {
+	command string
+	opts    remoteOptions
+	rerun   bool // run the command in the poll interval
+}
+
+// Read runs the command and returns its output.
+func (s *commandSource) Read() ([]byte, string, error) {
+	var stdout, stderr bytes.Buffer
+
+	ctx, cancel := s.opts.readContext()
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", s.command)
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", s.command)
+	}
+
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// children of the shell may keep the output open after it was killed
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, "", fmt.Errorf("config command timed out after %s: %w", s.opts.timeout, ctx.Err())
+		}
+
+		return nil, "", fmt.Errorf("config command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), s.opts.configType, nil
+}
+
+// Watch runs the command in the poll interval and sends a change if the
+// output changed. Without poll interval the returned channel is closed.
+func (s *commandSource) Watch(ctx context.Context) <-chan struct{} {
+	if !s.rerun {
+		changes := make(chan struct{})
+		close(changes)
+
+		return changes
+	}
+
+	return pollChanges(ctx, s.opts.pollInterval, func() ([]byte, error) {
+		data, _, err := s.Read()
+
+		return data, err
+	})
+}
@@ -4,16 +4,30 @@ package config
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
-	"github.com/fsnotify/fsnotify"
+	"filippo.io/age"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"github.com/urfave/cli/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Logger is a simple interface for logging.
@@ -40,6 +54,29 @@ type Loader[T any] interface {
 	StartWatcher() Dynamic[T]
 }
 
+// Manager is a Loader, which also inspects, changes and serves the
+// configuration at runtime. New returns a Manager.
+type Manager[T any] interface {
+	Loader[T]
+	Provenance() map[string]SourceInfo
+	Dump(w io.Writer, format string) error
+	Export(format string) ([]byte, error)
+	LastDiff() []Change
+	History() []Snapshot[T]
+	Rollback(n int) error
+	UnknownKeys() []string
+	EnvVars() []EnvVar
+	Set(keyPath string, value any) error
+	ApplyPatch(patch []byte) error
+	Save(path, format string) error
+	Healthy() error
+	HealthHandler() http.Handler
+	Handler() http.Handler
+	Reload() error
+	ReloadHandler() http.Handler
+	Close() error
+}
+
 // loader is a generic structure that loads and parses configuration.
 type loader[T any] struct {
 	config              atomic.Pointer[T] // Stores the current configuration
@@ -55,54 +92,161 @@ type loader[T any] struct {
 	exampleConfig       string    // shown if Parse fails, to give user a sample copy&paste example config
 	defaultConfig       T         // default config
 	defaultConfigSet    bool
+	reloadDebounce      time.Duration // coalesces change events within this window
+	debounceMu          sync.Mutex
+	debounceTimer       *time.Timer
+	debounceTrigger     string                        // trigger of the scheduled reload
+	reloadSignals       []os.Signal                   // signals which trigger a reload
+	pollInterval        time.Duration                 // polls the config file instead of using fsnotify
+	kubernetesWatcher   bool                          // follows symlink swaps of ConfigMap volumes
+	configFiles         []string                      // merged config files, later files override earlier ones
+	configDir           string                        // conf.d directory, all files are merged in lexical order
+	profile             string                        // profile overlay, e.g. "prod" for config.prod.yml
+	readerConfig        []byte                        // config data of WithConfigReader or WithOnlyEnv
+	sources             []Source                      // remote sources, merged on top of the config files
+	sourceCacheDir      string                        // caches the source data for outages of the backends
+	configType          string                        // format of the config files, derived from the extension if empty
+	retryAttempts       int                           // attempts to read a source
+	retryBackoff        BackoffFunc                   // delay between the attempts
+	cueSchema           []byte                        // CUE schema the config must satisfy
+	jsonnetImportPaths  []string                      // library paths of Jsonnet imports
+	jsonnetExtVars      map[string]string             // external variables of Jsonnet
+	timeLayouts         []string                      // layouts of time.Time fields in addition to RFC 3339
+	timeLocation        *time.Location                // time zone of time.Time fields
+	decodeHooks         []mapstructure.DecodeHookFunc // hooks of WithDecodeHook
+	envInterpolation    bool                          // expands ${VAR} in string values
+	fileReferences      bool                          // reads the files of "file://" values
+	secretResolvers     map[string]secretResolver     // resolvers of secret references by scheme
+	decrypters          []Decrypter                   // decrypt configs, e.g. SOPS encrypted configs
+	ageIdentities       []age.Identity                // decrypt age encrypted configs
+	signatureVerifier   SignatureVerifier             // verifies the signatures of config files
+	envReplacer         *envKeyReplacer               // maps keys to environment variables
+	dotEnvFiles         []string                      // dotenv files of WithDotEnv, read on every reload
+	dotEnv              map[string]string             // variables of the dotenv files
+	origins             map[string]SourceInfo         // sources of the keys of the config layer
+	permissionCheck     bool                          // checks the permissions of config files
+	maxFileMode         os.FileMode                   // permissions allowed by the permission check
+	permissionWarning   bool                          // logs insecure config files instead of rejecting them
+
+	provenance      atomic.Pointer[map[string]SourceInfo]    // sources of the keys of the parsed config
+	onConfigChange  func(previous, current T, diff []Change) // typed callback of reloads
+	lastDiff        atomic.Pointer[[]Change]                 // changes of the last reload
+	auditLog        io.Writer                                // audit log of reloads
+	auditMu         sync.Mutex                               // serializes the lines of the audit log
+	historySize     int                                      // number of snapshots of WithHistory
+	historyMu       sync.Mutex                               // guards history and version
+	history         []Snapshot[T]                            // snapshots, the current config last
+	version         int                                      // version of the last snapshot
+	stats           loadStats                                // statistics of parses and reloads
+	tracer          trace.Tracer                             // tracer of WithTracerProvider
+	healthThreshold time.Duration                            // time a source may be unreachable
+	preParseHooks   []func(raw map[string]any) error         // hooks of the raw settings
+	postParseHooks  []func(config *T) error                  // hooks of the decoded config
+	debugResolution bool                                     // logs the resolution of the fields
+	migrations      []migration                              // upgrades of old schema versions
+	deprecatedKeys  map[string]string                        // hints of deprecated keys by key
+	aliases         map[string]string                        // keys by alias of renamed keys
+	unknownKeys     atomic.Pointer[[]string]                 // keys of the config which map to no field
+	flagSets        []*pflag.FlagSet                         // command-line flags of WithFlagSet
+	goFlagSets      []*flag.FlagSet                          // command-line flags of WithGoFlagSet
+	flags           map[string]*pflag.Flag                   // bound flags by key
+	cliContexts     []*cli.Context                           // urfave/cli contexts of WithCLIContext
+	cliFlags        map[string]string                        // flags of the cli contexts by key
+	precedence      []Layer                                  // precedence of the layers of WithPrecedence
+	layers          map[Layer]map[string]layerValue          // values of the keys by layer
+	overrides       map[string]any                           // values set at runtime by key
+	adminToken      string                                   // bearer token of the reload endpoint
+	reloadMu        sync.Mutex                               // serializes the changes of viper by reloads, Set, ApplyPatch and Rollback
+	ctx             context.Context                          // canceled by Close, stops the watchers
+	cancel          context.CancelFunc                       // cancels ctx
 }
 
-// Ensure loader implements Loader
-var _ Loader[any] = (*loader[any])(nil)
+// Ensure loader implements Manager
+var _ Manager[any] = (*loader[any])(nil)
+
+// keyDelimiter separates the keys of nested config sections.
+const keyDelimiter = "_"
 
 // Option is a type for functional options.
 type Option[T any] func(*loader[T])
 
-// New creates a new Loader with functional options.
-func New[T any](opts ...Option[T]) Loader[T] {
-	// Create a new Viper instance with "_" as the key delimiter
-	viperInstance := viper.NewWithOptions(viper.KeyDelimiter("_"))
+// New creates a new Manager with functional options.
+func New[T any](opts ...Option[T]) Manager[T] {
+	l := newLoader(opts...)
 
-	l := &loader[T]{
-		config:              atomic.Pointer[T]{},
-		viper:               viperInstance,
-		disableAutomaticEnv: false,
-		subSection:          "",
-		onChangeCallback:    nil,
-		disableAutoParse:    false,
-		logger:              slogLogger{}, // Default to slog
-		useDefaultFilename:  true,
-		once:                sync.Once{},
+	if l.useDefaultFilename {
+		WithConfigFile[T]("config.yml")(l)
 	}
 
-	// Apply functional options
-	for _, opt := range opts {
-		opt(l)
+	ctx, span := l.startSpan(context.Background(), "config.Load")
+
+	if err := l.applyProfile(); err != nil {
+		l.logger.Error("Failed to apply profile", "profile", l.profile, "error", err)
 	}
 
-	if l.useDefaultFilename {
-		WithConfigFile[T]("config.yml")(l)
+	if err := l.mergeSources(); err != nil {
+		l.logger.Error("Failed to read config from source", "error", err)
+	}
+
+	if err := l.mergeDotEnv(); err != nil {
+		l.logger.Error("Failed to merge dotenv files", "error", err)
 	}
 
-	// Enable automatic environment variables
-	if !l.disableAutomaticEnv {
+	// Enable automatic environment variables, sections only use env tags
+	if !l.disableAutomaticEnv && l.subSection == "" {
 		l.viper.AutomaticEnv()
 	}
 
 	// Parse the configuration initially unless disabled
 	if !l.disableAutoParse {
-		if err := l.Parse(); err != nil {
+		err := l.parseContext(ctx)
+		endSpan(span, err)
+
+		if err != nil {
 			if !l.defaultConfigSet {
 				panic("Failed to load config: " + err.Error())
 			}
 
 			l.config.Store(&l.defaultConfig)
+			l.stats.recordDefault(err)
 		}
+	} else {
+		endSpan(span, nil)
+	}
+
+	if len(l.reloadSignals) > 0 {
+		l.watchSignals()
+	}
+
+	return l
+}
+
+// newLoader creates a loader and applies the options, without reading the
+// sources, parsing or watching.
+func newLoader[T any](opts ...Option[T]) *loader[T] {
+	// Create a new Viper instance with "_" as the key delimiter, the replacer
+	// hides the environment from noenv fields
+	envReplacer := &envKeyReplacer{}
+	viperInstance := viper.NewWithOptions(viper.KeyDelimiter(keyDelimiter), viper.EnvKeyReplacer(envReplacer))
+
+	l := &loader[T]{
+		config:              atomic.Pointer[T]{},
+		viper:               viperInstance,
+		envReplacer:         envReplacer,
+		disableAutomaticEnv: false,
+		subSection:          "",
+		onChangeCallback:    nil,
+		disableAutoParse:    false,
+		logger:              slogLogger{}, // Default to slog
+		useDefaultFilename:  true,
+		once:                sync.Once{},
+	}
+
+	l.ctx, l.cancel = context.WithCancel(context.Background())
+
+	// Apply functional options
+	for _, opt := range opts {
+		opt(l)
 	}
 
 	return l
@@ -119,6 +263,7 @@ func WithOnlyEnv[T any]() Option[T] {
 
 		cfgBytes, _ := json.Marshal(cfg)
 		cl.viper.SetConfigType("json")
+		cl.readerConfig = cfgBytes
 
 		if err := cl.viper.ReadConfig(bytes.NewReader(cfgBytes)); err != nil {
 			cl.logger.Error("Failed to read config from reader", "error", err)
@@ -134,19 +279,131 @@ func WithConfigFile[T any](configName string) Option[T] {
 		cl.useDefaultFilename = false
 		cl.viper.SetConfigFile(configName)
 
-		if err := cl.viper.ReadInConfig(); err != nil {
+		if err := cl.readInConfig(); err != nil {
 			cl.logger.Error("Failed to read config from file", "error", err)
 		}
 	}
 }
 
+// WithConfigType is an option to set the format of the config files, e.g.
+// "toml" for a file without extension. By default the format is derived from
+// the file extension.
+func WithConfigType[T any](configType string) Option[T] {
+	return func(cl *loader[T]) {
+		cl.configType = configType
+		cl.viper.SetConfigType(configType)
+
+		// files given by earlier options are read again in the format
+		if cl.hasConfigFiles() {
+			if err := cl.readConfig(); err != nil {
+				cl.logger.Error("Failed to read config from file", "error", err)
+			}
+		}
+	}
+}
+
+// WithConfigFiles is an option to load and merge multiple configuration files.
+// Later files override keys of earlier files, the watcher monitors all of them.
+func WithConfigFiles[T any](configNames ...string) Option[T] {
+	return func(cl *loader[T]) {
+		cl.useDefaultFilename = false
+		cl.configFiles = configNames
+
+		if err := cl.readConfigFiles(); err != nil {
+			cl.logger.Error("Failed to read config from file", "error", err)
+		}
+	}
+}
+
+// WithConfigDir is an option to load all *.yml, *.yaml, *.json, *.toml, *.hcl,
+// *.ini, *.properties, *.jsonc, *.cue, *.jsonnet, *.txtpb, *.textproto
+// files and the files of registered codecs of a conf.d directory. The files are merged in lexical
+// order, the watcher monitors the directory for added, changed and removed
+// files.
+func WithConfigDir[T any](dir string) Option[T] {
+	return func(cl *loader[T]) {
+		cl.useDefaultFilename = false
+		cl.configDir = filepath.Clean(dir)
+
+		if err := cl.readConfigFiles(); err != nil {
+			cl.logger.Error("Failed to read config from directory", "error", err)
+		}
+	}
+}
+
+var errNoConfigFiles = errors.New("no config files found")
+
+// configDirExts are the file extensions loaded from a config directory.
+var configDirExts = []string{".yml", ".yaml", ".json", ".toml", ".hcl", ".ini", ".properties", ".jsonc", ".cue", ".jsonnet", ".txtpb", ".textproto"}
+
+// sourceFiles returns the config files to merge. Files of a config directory
+// are returned in lexical order.
+func (c *loader[T]) sourceFiles() ([]string, error) {
+	if c.configDir == "" {
+		return slices.Clone(c.configFiles), nil
+	}
+
+	entries, err := os.ReadDir(c.configDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var configFiles []string
+
+	for _, entry := range entries {
+		if !entry.IsDir() && isConfigExt(configExt(entry.Name())) {
+			configFiles = append(configFiles, filepath.Join(c.configDir, entry.Name()))
+		}
+	}
+
+	if len(configFiles) == 0 {
+		return nil, fmt.Errorf("%w in %s", errNoConfigFiles, c.configDir)
+	}
+
+	return configFiles, nil
+}
+
+// readConfigFiles reads the first config file and merges all further files.
+func (c *loader[T]) readConfigFiles() error {
+	configFiles, err := c.sourceFiles()
+	if err != nil {
+		return err
+	}
+
+	for i, configFile := range configFiles {
+		c.viper.SetConfigFile(configFile)
+
+		readConfig := c.mergeInConfig
+		if i == 0 {
+			readConfig = c.readInConfig
+		}
+
+		if err := readConfig(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // WithConfigReader is an option to load configuration from an io.Reader.
 func WithConfigReader[T any](reader io.Reader, configType string) Option[T] {
 	return func(cl *loader[T]) {
 		cl.useDefaultFilename = false
+		cl.configType = configType
 		cl.viper.SetConfigType(configType)
 
-		if err := cl.viper.ReadConfig(reader); err != nil {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			cl.logger.Error("Failed to read config from reader", "error", err)
+
+			return
+		}
+
+		// Keep the data to restore the config on reloads
+		cl.readerConfig = data
+
+		if err := cl.readConfigData(data); err != nil {
 			cl.logger.Error("Failed to read config from reader", "error", err)
 		}
 	}
@@ -159,16 +416,24 @@ func WithConfigPath[T any](configPaths []string) Option[T] {
 			cl.viper.AddConfigPath(configPath)
 		}
 
-		if err := cl.viper.ReadInConfig(); err != nil {
+		// viper searches the config file, which is read like other files
+		err := cl.viper.ReadInConfig()
+		if err == nil {
+			err = cl.readInConfig()
+		}
+
+		if err != nil {
 			cl.logger.Error("Failed to read config from file", "error", err)
 		}
 	}
 }
 
-// WithViperInstance is an option to provide a custom Viper instance.
+// WithViperInstance is an option to provide a custom Viper instance. Fields
+// tagged with noenv are not protected from the environment of the instance.
 func WithViperInstance[T any](v *viper.Viper) Option[T] {
 	return func(cl *loader[T]) {
 		cl.viper = v
+		cl.envReplacer = nil
 	}
 }
 
@@ -179,7 +444,31 @@ func DisableAutomaticEnv[T any]() Option[T] {
 	}
 }
 
-// WithSubSection is an option to load only a SubSection.
+// WithEnvPrefix is an option to set a prefix for environment variables, e.g.
+// "MYAPP" reads MYAPP_DATABASECONFIG_HOST instead of DATABASECONFIG_HOST.
+func WithEnvPrefix[T any](prefix string) Option[T] {
+	return func(cl *loader[T]) {
+		cl.viper.SetEnvPrefix(prefix)
+	}
+}
+
+// WithEnvKeyReplacer is an option to set a replacer, which maps config keys to
+// environment variable names. Nested keys are joined with "_" before replacing,
+// e.g. strings.NewReplacer("_", "__") reads DATABASECONFIG__HOST.
+func WithEnvKeyReplacer[T any](replacer *strings.Replacer) Option[T] {
+	return func(cl *loader[T]) {
+		if cl.envReplacer == nil {
+			cl.viper.SetEnvKeyReplacer(replacer)
+
+			return
+		}
+
+		cl.envReplacer.replacer = replacer
+	}
+}
+
+// WithSubSection is an option to load only a SubSection. Environment variables
+// only apply to the section by env tags, not automatically.
 func WithSubSection[T any](section string) Option[T] {
 	return func(cl *loader[T]) {
 		cl.subSection = section
@@ -210,6 +499,36 @@ func WithDefault[T any](config T) Option[T] {
 	}
 }
 
+// WithReloadDebounce is an option to coalesce change events that occur within
+// the given duration into a single reload. Editors and atomic writes often emit
+// several events for one change.
+func WithReloadDebounce[T any](d time.Duration) Option[T] {
+	return func(cl *loader[T]) {
+		cl.reloadDebounce = d
+	}
+}
+
+// WithDefaultsFromReader is an option to read default values from a config,
+// e.g. an embedded base config. Unlike WithDefault the defaults are merged per
+// key, so the config file only needs to contain the values which differ.
+func WithDefaultsFromReader[T any](reader io.Reader, configType string) Option[T] {
+	return func(cl *loader[T]) {
+		defaults := viper.NewWithOptions(viper.KeyDelimiter(keyDelimiter))
+		defaults.SetConfigType(configType)
+
+		if err := defaults.ReadConfig(reader); err != nil {
+			cl.logger.Error("Failed to read defaults from reader", "error", err)
+
+			return
+		}
+
+		for _, key := range defaults.AllKeys() {
+			cl.viper.SetDefault(key, defaults.Get(key))
+			cl.setLayerValue(key, defaults.Get(key), SourceInfo{Kind: KindDefault})
+		}
+	}
+}
+
 // DisableAutoParse is an option to disable automatic parsing in New(), this prevents panic when no config was found.
 // The Parse() function needs to be called after New() and before Load().
 func DisableAutoParse[T any]() Option[T] {
@@ -228,8 +547,44 @@ func WithLogger[T any](logger Logger) Option[T] {
 var errSectionNotFound = errors.New("section not found in config")
 
 // Parse parses the configuration it into the generic struct.
-// If subsection set, only the specified subsection is parsed.
+// If subsection set, only the specified subsection is parsed. Values of fields
+// tagged with secret:"true" or redact:"true" are masked in the error.
 func (c *loader[T]) Parse() error {
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+
+	return c.parseContext(context.Background())
+}
+
+// parseContext parses the configuration like Parse in a span of the trace of
+// ctx.
+func (c *loader[T]) parseContext(ctx context.Context) error {
+	_, span := c.startSpan(ctx, "config.Parse")
+	start := time.Now()
+
+	if err := c.parse(); err != nil {
+		c.stats.recordParse(time.Since(start), "")
+
+		err = c.redactError(err)
+		endSpan(span, err)
+
+		return err
+	}
+
+	settings, _ := c.settings()
+	sum := checksum(settings)
+
+	c.stats.recordParse(time.Since(start), sum)
+	c.addSnapshot(*c.config.Load(), sum)
+
+	span.SetAttributes(attribute.String("config.checksum", sum))
+	endSpan(span, nil)
+
+	return nil
+}
+
+// parse parses the configuration like Parse.
+func (c *loader[T]) parse() error {
 	var config T
 
 	var exampleText string
@@ -237,29 +592,115 @@ func (c *loader[T]) Parse() error {
 		exampleText = fmt.Sprintf("\nExample Config:\n%s\n", c.exampleConfig)
 	}
 
+	if err := c.checkFiles(); err != nil {
+		return err
+	}
+
+	if err := c.applyAliases(); err != nil {
+		return fmt.Errorf("failed to apply aliases: %w", err)
+	}
+
+	c.setNoEnvFields()
+
+	if err := bindEnvTags(c.viper, reflect.TypeOf(config), c.subSection); err != nil {
+		return fmt.Errorf("failed to bind env tags: %w", err)
+	}
+
+	if err := c.bindFlags(); err != nil {
+		return fmt.Errorf("failed to bind flags: %w", err)
+	}
+
 	// Extract the subsection if specified
+	settings, name := c.allSettings(), "config"
+
 	if c.subSection != "" {
-		sub := c.viper.Sub(c.subSection)
+		sub := c.sub(c.subSection)
 		if sub == nil {
 			return fmt.Errorf("%w: \"%s\"%s", errSectionNotFound, c.subSection, exampleText)
 		}
 
-		if err := sub.Unmarshal(&config); err != nil {
-			return fmt.Errorf("failed to unmarshal section %s: %w%s", c.subSection, err, exampleText)
+		settings, name = sub.AllSettings(), "section "+c.subSection
+	}
+
+	settings, err := c.migrate(settings)
+	if err != nil {
+		return fmt.Errorf("failed to migrate %s: %w%s", name, err, exampleText)
+	}
+
+	if err := c.validate(settings); err != nil {
+		return fmt.Errorf("invalid %s: %w%s", name, err, exampleText)
+	}
+
+	for _, hook := range c.preParseHooks {
+		if err := hook(settings); err != nil {
+			return fmt.Errorf("pre-parse hook of %s failed: %w%s", name, err, exampleText)
 		}
-	} else {
-		// Parse the entire configuration
-		if err := c.viper.Unmarshal(&config); err != nil {
-			return fmt.Errorf("failed to unmarshal config: %w%s", err, exampleText)
+	}
+
+	unused, err := c.unmarshal(settings, &config)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %w%s", name, err, exampleText)
+	}
+
+	for _, hook := range c.postParseHooks {
+		if err := hook(&config); err != nil {
+			return fmt.Errorf("post-parse hook of %s failed: %w%s", name, err, exampleText)
 		}
 	}
 
 	// Store the configuration in the atomic.Pointer
 	c.config.Store(&config)
+	c.storeProvenance()
+	c.storeUnknownKeys(unused)
+
+	c.warnDeprecated()
+
+	if c.debugResolution {
+		c.logResolution()
+	}
 
 	return nil
 }
 
+// validate validates the settings against the CUE schema of WithCUESchema.
+func (c *loader[T]) validate(settings map[string]any) error {
+	if c.cueSchema == nil {
+		return nil
+	}
+
+	return c.validateCUE(settings)
+}
+
+// sub returns a viper instance of the section. Unlike viper.Sub, the section
+// contains the merged values of all layers, including defaults, env tags and
+// values set at runtime. Returns nil if the section does not exist.
+func (c *loader[T]) sub(section string) *viper.Viper {
+	var value any = c.allSettings()
+
+	for _, key := range strings.Split(strings.ToLower(section), keyDelimiter) {
+		settings, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+
+		if value, ok = settings[key]; !ok {
+			return nil
+		}
+	}
+
+	settings, ok := value.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	sub := viper.NewWithOptions(viper.KeyDelimiter(keyDelimiter))
+	if err := sub.MergeConfigMap(settings); err != nil {
+		return nil
+	}
+
+	return sub
+}
+
 // Load returns the latest parsed configuration.
 func (c *loader[T]) Load() T {
 	return *c.config.Load()
@@ -275,29 +716,131 @@ func (c *loader[T]) SetOnChangeFunc(fn func(error)) {
 // Optional returns an dynamic conf Loader, but the loader[T] instance also can be used.
 func (c *loader[T]) StartWatcher() Dynamic[T] {
 	c.once.Do(func() {
-		// Register a callback for configuration changes
-		c.viper.OnConfigChange(func(event fsnotify.Event) {
-			err := c.Parse() // Section is passed here
-			if err != nil {
-				c.logger.Error("Failed to reload config", "error", err)
-			} else {
-				c.logger.Info("Config reloaded successfully")
-			}
+		c.watchSources()
 
-			if c.onChangeCallback != nil {
-				c.onChangeCallback(err) // Call the callback function with the error (if any)
-			}
-		})
+		if !c.hasConfigFiles() {
+			return
+		}
+
+		if c.pollInterval > 0 {
+			go c.pollConfigFiles()
 
-		go func() {
-			// Enable watching for file changes
-			c.viper.WatchConfig()
-		}()
+			return
+		}
+
+		// the files are read by the reload, not by viper.WatchConfig, so they
+		// are checked and the reads are serialized with the other changes
+		go c.watchConfigFiles()
 	})
 
 	return c
 }
 
+// Close stops the watchers of the config files, the sources and the signals
+// and a scheduled reload. The last config can still be loaded.
+func (c *loader[T]) Close() error {
+	c.cancel()
+
+	c.debounceMu.Lock()
+	if c.debounceTimer != nil {
+		c.debounceTimer.Stop()
+	}
+	c.debounceMu.Unlock()
+
+	return nil
+}
+
+// triggerReload reloads the configuration, or schedules the reload if a
+// debounce window is set. Further triggers within the window postpone it.
+// The trigger is the cause of the reload, e.g. triggerFile.
+func (c *loader[T]) triggerReload(trigger string) {
+	if c.reloadDebounce <= 0 {
+		c.reload(trigger)
+
+		return
+	}
+
+	c.debounceMu.Lock()
+	defer c.debounceMu.Unlock()
+
+	c.debounceTrigger = trigger
+
+	if c.debounceTimer == nil {
+		c.debounceTimer = time.AfterFunc(c.reloadDebounce, func() {
+			c.debounceMu.Lock()
+			trigger := c.debounceTrigger
+			c.debounceMu.Unlock()
+
+			c.reload(trigger)
+		})
+
+		return
+	}
+
+	c.debounceTimer.Reset(c.reloadDebounce)
+}
+
+// reload reads and parses the configuration and reports the result to the
+// logger, the audit log and the change callbacks.
+func (c *loader[T]) reload(trigger string) error {
+	ctx, span := c.startSpan(context.Background(), "config.Reload", attribute.String("config.trigger", trigger))
+
+	c.reloadMu.Lock()
+
+	previous := c.config.Load()
+	previousSettings, _ := c.settings()
+
+	err := c.redactError(c.reread())
+	if err == nil {
+		err = c.parseContext(ctx) // Section is passed here
+	}
+
+	c.stats.recordReload(err)
+
+	var diff []Change
+
+	if err != nil {
+		c.logger.Error("Failed to reload config", "error", err)
+		c.audit(trigger, err, nil, nil)
+	} else {
+		diff = c.publish(span, trigger, previousSettings)
+		c.logger.Info("Config reloaded successfully", "changes", len(diff))
+	}
+
+	current := c.config.Load()
+	c.reloadMu.Unlock()
+
+	endSpan(span, err)
+	c.notify(previous, current, diff, err)
+
+	return err
+}
+
+// publish reports the changes of the config after a successful reload or Set
+// to the trace and the audit log and returns them.
+func (c *loader[T]) publish(span trace.Span, trigger string, previousSettings map[string]any) []Change {
+	currentSettings, _ := c.settings()
+	diff := c.diffSettings(previousSettings, currentSettings)
+	c.lastDiff.Store(&diff)
+
+	changeEvent(span, diff)
+	c.audit(trigger, nil, diff, currentSettings)
+
+	return diff
+}
+
+// notify calls the change callbacks with the result of a reload, outside of
+// reloadMu, so the callbacks may reload or set values themselves.
+func (c *loader[T]) notify(previous, current *T, diff []Change, err error) {
+	if err == nil && c.onConfigChange != nil && previous != nil {
+		c.onConfigChange(*previous, *current, diff)
+	}
+
+	if c.onChangeCallback != nil {
+		c.onChangeCallback(err) // Call the callback function with the error (if any)
+	}
+}
+
 type Dynamic[T any] interface {
 	Load() T
 	SetOnChangeFunc(func(error))
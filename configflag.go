@@ -0,0 +1,58 @@
+package config
+
+import (
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// WithConfigFlag is an option to read the config files of the --config and
+// -c flags of the command line, before the flags are parsed. The flag may be
+// given multiple times, the files are merged in order like for
+// WithConfigFiles. Without the flag the config files of other options are
+// read. Register the flag with RegisterConfigFlag to show it in the usage.
+func WithConfigFlag[T any]() Option[T] {
+	return func(cl *loader[T]) {
+		if configFiles := ConfigFiles(os.Args[1:]); len(configFiles) > 0 {
+			WithConfigFiles[T](configFiles...)(cl)
+		}
+	}
+}
+
+// ConfigFiles returns the values of the --config and -c flags of args, e.g.
+// "--config=a.yml", "--config a.yml", "-c a.yml" or "-config a.yml" of the
+// flag package. Arguments after "--" are no flags.
+func ConfigFiles(args []string) []string {
+	var configFiles []string
+
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--" {
+			break
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+		if !strings.HasPrefix(args[i], "-") || (name != configFlag && name != "c") {
+			continue
+		}
+
+		if !hasValue {
+			if i+1 >= len(args) {
+				break
+			}
+
+			i++
+			value = args[i]
+		}
+
+		configFiles = append(configFiles, value)
+	}
+
+	return configFiles
+}
+
+// RegisterConfigFlag registers the --config and -c flags of WithConfigFlag in
+// fs, so parsing the command line accepts them.
+func RegisterConfigFlag(fs *pflag.FlagSet) {
+	fs.StringArrayP(configFlag, "c", nil, "config file, repeat to merge multiple files in order")
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// consulWaitTime is the maximum duration of a blocking query.
+const consulWaitTime = 5 * time.Minute
+
+// WithConsul is an option to load the config from a Consul KV key. The key is
+// watched by StartWatcher with blocking queries and every change triggers a
+// reload. The format is derived from the key extension, e.g. "myapp/config.yml",
+// unless WithFormat is used. WithBearerToken sets the Consul ACL token.
+func WithConsul[T any](address, key string, opts ...RemoteOption) Option[T] {
+	return func(cl *loader[T]) {
+		cl.addSource(ConsulSource(address, key, opts...))
+	}
+}
+
+// ConsulSource returns the source of WithConsul, e.g. for WithSources.
+func ConsulSource(address, key string, opts ...RemoteOption) Source {
+	return &consulSource{
+		address: strings.TrimSuffix(address, "/"),
+		key:     strings.TrimPrefix(key, "/"),
+		opts:    newRemoteOptions(key, opts),
+	}
+}
+
+// consulSource reads and watches a key of the Consul KV store.
+type consulSource struct {
+	address string
+	key     string
+	opts    remoteOptions
+}
+
+// Read returns the value of the key.
+func (s *consulSource) Read() ([]byte, string, error) {
+	ctx, cancel := s.opts.readContext()
+	defer cancel()
+
+	resp, err := s.get(ctx, 0)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, s.opts.configType, nil
+}
+
+// Watch sends a change whenever the modify index of the key increased. A
+// failed query is retried in the poll interval and a change is sent, so
+// updates missed meanwhile are read.
+func (s *consulSource) Watch(ctx context.Context) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+
+	go func() {
+		defer close(changes)
+
+		var index uint64
+
+		for {
+			newIndex, err := s.index(ctx, index)
+			if err != nil {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(s.opts.pollInterval):
+					notify(changes)
+				}
+
+				continue
+			}
+
+			switch {
+			case newIndex < index:
+				// The index went backwards, e.g. after a restore, start over
+				index = 0
+			case index != 0 && newIndex > index:
+				index = newIndex
+				notify(changes)
+			default:
+				index = newIndex
+			}
+		}
+	}()
+
+	return changes
+}
+
+// index returns the modify index of the key, blocking until it is greater
+// than index if index is set.
+func (s *consulSource) index(ctx context.Context, index uint64) (uint64, error) {
+	resp, err := s.get(ctx, index)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	newIndex, err := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	if err != nil || newIndex == 0 {
+		return 0, fmt.Errorf("%w: invalid X-Consul-Index", errUnexpectedResponse)
+	}
+
+	return newIndex, nil
+}
+
+// get queries the raw value of the key, blocking until the modify index is
+// greater than index if index is set.
+func (s *consulSource) get(ctx context.Context, index uint64) (*http.Response, error) {
+	query := url.Values{"raw": {""}}
+	if index > 0 {
+		query.Set("index", strconv.FormatUint(index, 10))
+		query.Set("wait", consulWaitTime.String())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s/v1/kv/%s?%s", s.address, s.key, query.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.opts.token != "" {
+		req.Header.Set("X-Consul-Token", s.opts.token)
+	}
+
+	return doRequest(s.opts.httpClient, req)
+}
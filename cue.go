@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+)
+
+// WithCUESchema is an option to validate the config against a CUE schema file,
+// e.g. `databaseConfig: port: int & >0 & <65536`. Parse returns the constraint
+// errors of CUE if the config does not satisfy the schema. Field names of the
+// schema are matched case-insensitively like the keys on Unmarshal, with
+// WithSubSection the schema describes the section.
+func WithCUESchema[T any](path string) Option[T] {
+	return func(cl *loader[T]) {
+		schema, err := os.ReadFile(path)
+		if err != nil {
+			cl.logger.Error("Failed to read CUE schema", "path", path, "error", err)
+
+			return
+		}
+
+		cl.cueSchema = schema
+	}
+}
+
+// decodeCUE evaluates a CUE file, the result must be concrete.
+func decodeCUE(data []byte) (map[string]any, error) {
+	value := cuecontext.New().CompileBytes(data)
+	if err := value.Validate(cue.Concrete(true)); err != nil {
+		return nil, err
+	}
+
+	settings := make(map[string]any)
+	if err := value.Decode(&settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// validateCUE unifies the settings with the CUE schema and validates the
+// result.
+func (c *loader[T]) validateCUE(settings map[string]any) error {
+	ctx := cuecontext.New()
+
+	schema := ctx.CompileBytes(c.cueSchema)
+	if err := schema.Err(); err != nil {
+		return fmt.Errorf("invalid CUE schema: %w", err)
+	}
+
+	return schema.Unify(ctx.Encode(schemaKeys(settings, schema))).Validate(cue.Concrete(true))
+}
+
+// schemaKeys returns a copy of the lower-cased settings of viper with the field
+// names of the schema, matched case-insensitively. Whole numbers of JSON are
+// converted to integers to satisfy the int constraints.
+func schemaKeys(settings map[string]any, schema cue.Value) map[string]any {
+	labels := make(map[string]string)
+
+	if fields, err := schema.Fields(cue.Optional(true)); err == nil {
+		for fields.Next() {
+			labels[strings.ToLower(fields.Selector().Unquoted())] = fields.Selector().Unquoted()
+		}
+	}
+
+	renamed := make(map[string]any, len(settings))
+
+	for key, value := range settings {
+		if label, ok := labels[strings.ToLower(key)]; ok {
+			key = label
+		}
+
+		switch typed := value.(type) {
+		case map[string]any:
+			value = schemaKeys(typed, schema.LookupPath(cue.MakePath(cue.Str(key))))
+		case float64:
+			if typed == math.Trunc(typed) && math.Abs(typed) < 1<<53 {
+				value = int64(typed)
+			}
+		}
+
+		renamed[key] = value
+	}
+
+	return renamed
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// WithDebugResolution is an option to log the resolution of every field after
+// a parse: the key which was looked up, the sources which were consulted in
+// the order of precedence, the source whose value won and the value. Values of
+// secret fields are redacted.
+func WithDebugResolution[T any]() Option[T] {
+	return func(cl *loader[T]) {
+		cl.debugResolution = true
+	}
+}
+
+// logResolution logs the resolution of the fields of the parsed config.
+func (c *loader[T]) logResolution() {
+	keys := c.viper.AllKeys()
+	envNames := c.envTagNames()
+	secrets := c.secretKeys()
+
+	provenance := c.provenance.Load()
+	if provenance == nil {
+		return
+	}
+
+	_ = walkFields(reflect.TypeFor[T](), strings.ToLower(c.subSection), func(key string, field reflect.StructField) error {
+		// sections are resolved by their nested keys
+		if !slices.Contains(keys, key) && slices.ContainsFunc(keys, func(k string) bool {
+			return strings.HasPrefix(k, key+keyDelimiter)
+		}) {
+			return nil
+		}
+
+		source, value := "unset", any(nil)
+		if info, ok := (*provenance)[key]; ok {
+			source, value = info.String(), c.viper.Get(key)
+		}
+
+		if isSecretKey(key, secrets) {
+			value = redactValue(value)
+		}
+
+		c.logger.Info("Resolved config key", "field", field.Name, "key", key,
+			"consulted", strings.Join(c.consultedSources(key, field, envNames), ", "),
+			"source", source, "value", value)
+
+		return nil
+	})
+}
+
+// consultedSources returns the sources which are consulted for the key in the
+// order of precedence.
+func (c *loader[T]) consultedSources(key string, field reflect.StructField, envNames map[string]string) []string {
+	precedence := c.precedence
+	if precedence == nil {
+		precedence = defaultPrecedence
+	}
+
+	var sources []string
+
+	if _, ok := c.overrides[key]; ok {
+		sources = append(sources, SourceInfo{Kind: KindRuntime}.String())
+	}
+
+	for _, layer := range precedence {
+		switch layer {
+		case Flags:
+			if name, ok := c.cliFlags[key]; ok {
+				sources = append(sources, SourceInfo{Kind: KindFlag, Name: name}.String())
+			}
+
+			if flag, ok := c.flags[key]; ok {
+				sources = append(sources, SourceInfo{Kind: KindFlag, Name: "--" + flag.Name}.String())
+			}
+		case Env:
+			if isNoEnv(field) {
+				continue
+			}
+
+			if name, ok := envNames[key]; ok {
+				sources = append(sources, SourceInfo{Kind: KindEnv, Name: name}.String())
+			}
+
+			if name := c.automaticEnvName(key); name != "" && name != envNames[key] {
+				sources = append(sources, SourceInfo{Kind: KindEnv, Name: name}.String())
+			}
+		case Defaults:
+			sources = append(sources, SourceInfo{Kind: KindDefault}.String())
+		default:
+			if v, ok := c.layers[layer][key]; ok {
+				sources = append(sources, v.info.String())
+			}
+		}
+	}
+
+	return sources
+}
@@ -0,0 +1,395 @@
+package config
+
+import (
+	"cmp"
+	"encoding"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/spf13/viper"
+)
+
+// decodeHook returns the decode hook of Unmarshal. The hooks run in order: the
+// environment variables of WithEnvInterpolation, the secret references of
+// WithSecretResolver, the hooks of WithDecodeHook, "base64:" values, the
+// "file://" references of WithFileReferences, durations, byte sizes, URLs, IP addresses, regular expressions,
+// log levels, times, TOML local dates and times, types implementing
+// encoding.TextUnmarshaler, the viper defaults and protobuf messages.
+func (c *loader[T]) decodeHook() viper.DecoderConfigOption {
+	tomlLocation := time.Local
+	if c.timeLocation != nil {
+		tomlLocation = c.timeLocation
+	}
+
+	var hooks []mapstructure.DecodeHookFunc
+	if c.envInterpolation {
+		hooks = append(hooks, interpolationHook(c.lookupEnv))
+	}
+
+	if len(c.secretResolvers) > 0 {
+		hooks = append(hooks, secretHook(c.secretResolvers))
+	}
+
+	hooks = append(hooks, c.decodeHooks...)
+	hooks = append(hooks, base64Hook)
+
+	if c.fileReferences {
+		hooks = append(hooks, fileReferenceHook)
+	}
+
+	hooks = append(hooks,
+		durationHook,
+		byteSizeHook,
+		urlHook,
+		networkHook,
+		regexpHook,
+		logLevelHook,
+		timeHook(c.timeLayouts, c.timeLocation),
+		tomlLocalTimeHook(tomlLocation),
+		textUnmarshalerHook,
+		mapstructure.StringToSliceHookFunc(","),
+		protoMessageHook,
+	)
+
+	return viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(hooks...))
+}
+
+// unmarshal decodes the settings into config like viper.Unmarshal with the
+// decode hook, it returns the keys which did not map to a field.
+func (c *loader[T]) unmarshal(settings map[string]any, config *T) ([]string, error) {
+	var metadata mapstructure.Metadata
+
+	decoderConfig := &mapstructure.DecoderConfig{
+		Result:           config,
+		WeaklyTypedInput: true,
+		Metadata:         &metadata,
+	}
+	c.decodeHook()(decoderConfig)
+
+	decoder, err := mapstructure.NewDecoder(decoderConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decoder.Decode(settings); err != nil {
+		return nil, err
+	}
+
+	return metadata.Unused, nil
+}
+
+// WithDecodeHook is an option to add mapstructure decode hooks for project
+// specific conversions. The hooks run before the built-in hooks, in order.
+func WithDecodeHook[T any](hooks ...mapstructure.DecodeHookFunc) Option[T] {
+	return func(cl *loader[T]) {
+		cl.decodeHooks = append(cl.decodeHooks, hooks...)
+	}
+}
+
+// WithTimeLayouts is an option to decode time.Time fields from strings in the
+// layouts of time.Parse, e.g. "2006-01-02", in addition to RFC 3339.
+func WithTimeLayouts[T any](layouts ...string) Option[T] {
+	return func(cl *loader[T]) {
+		cl.timeLayouts = append(cl.timeLayouts, layouts...)
+	}
+}
+
+// WithTimeLocation is an option to set the time zone of time.Time fields:
+// times of layouts without a time zone and TOML local dates are in the
+// location, all decoded times are converted to it. The default are times in UTC
+// and TOML local dates in the local time zone.
+func WithTimeLocation[T any](location *time.Location) Option[T] {
+	return func(cl *loader[T]) {
+		cl.timeLocation = location
+	}
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	bytesType    = reflect.TypeOf([]byte(nil))
+	urlType      = reflect.TypeOf(url.URL{})
+	ipType       = reflect.TypeOf(net.IP{})
+	ipNetType    = reflect.TypeOf(net.IPNet{})
+	addrType     = reflect.TypeOf(netip.Addr{})
+	prefixType   = reflect.TypeOf(netip.Prefix{})
+	addrPortType = reflect.TypeOf(netip.AddrPort{})
+	regexpType   = reflect.TypeOf(regexp.Regexp{})
+	levelType    = reflect.TypeOf(slog.Level(0))
+)
+
+// base64Prefix marks base64 encoded values, e.g. "base64:LS0tLS1CRUdJTi...".
+const base64Prefix = "base64:"
+
+// base64Hook decodes base64 values with the "base64:" prefix into []byte and
+// string fields, padded or unpadded.
+func base64Hook(_ reflect.Type, to reflect.Type, data any) (any, error) {
+	value, ok := data.(string)
+	if !ok || !strings.HasPrefix(value, base64Prefix) || (to != bytesType && to.Kind() != reflect.String) {
+		return data, nil
+	}
+
+	// line breaks of YAML block scalars are ignored
+	encoded := strings.TrimRight(strings.Join(strings.Fields(strings.TrimPrefix(value, base64Prefix)), ""), "=")
+
+	decoded, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 value: %w", err)
+	}
+
+	if to == bytesType {
+		return decoded, nil
+	}
+
+	return string(decoded), nil
+}
+
+// filePrefix marks references to files, e.g. "file:///run/secrets/password".
+const filePrefix = "file://"
+
+// WithFileReferences is an option to replace "file://" values of []byte and
+// string fields by the content of the referenced file, e.g.
+// "file:///run/secrets/db_password". It is opt-in, as anyone who can set a
+// config value could read any file the process can read.
+func WithFileReferences[T any]() Option[T] {
+	return func(cl *loader[T]) {
+		cl.fileReferences = true
+	}
+}
+
+// fileReferenceHook reads the referenced file of "file://" values into []byte
+// and string fields. Trailing line breaks are removed for string fields. The
+// file is read on every Parse, so reloads pick up changed files.
+func fileReferenceHook(_ reflect.Type, to reflect.Type, data any) (any, error) {
+	value, ok := data.(string)
+	if !ok || !strings.HasPrefix(value, filePrefix) || (to != bytesType && to.Kind() != reflect.String) {
+		return data, nil
+	}
+
+	content, err := os.ReadFile(strings.TrimPrefix(value, filePrefix))
+	if err != nil {
+		return nil, err
+	}
+
+	if to == bytesType {
+		return content, nil
+	}
+
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// durationHook decodes strings like "30s" or "1h30m" into time.Duration
+// fields, in every config format and in environment variables.
+func durationHook(_ reflect.Type, to reflect.Type, data any) (any, error) {
+	value, ok := data.(string)
+	if !ok || to != durationType {
+		return data, nil
+	}
+
+	duration, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("invalid duration %q, expected a number with a unit like \"30s\" or \"5m\"", value)
+	}
+
+	return duration, nil
+}
+
+// urlHook decodes strings into url.URL and *url.URL fields.
+func urlHook(_ reflect.Type, to reflect.Type, data any) (any, error) {
+	value, ok := data.(string)
+	if !ok || (to != urlType && to != reflect.PointerTo(urlType)) {
+		return data, nil
+	}
+
+	parsed, err := url.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	return parsed, nil
+}
+
+// networkHook decodes strings into net.IP, net.IPNet, netip.Addr, netip.Prefix
+// and netip.AddrPort fields.
+func networkHook(_ reflect.Type, to reflect.Type, data any) (any, error) {
+	value, ok := data.(string)
+	if !ok {
+		return data, nil
+	}
+
+	value = strings.TrimSpace(value)
+
+	switch to {
+	case ipType:
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address %q", value)
+		}
+
+		return ip, nil
+	case ipNetType, reflect.PointerTo(ipNetType):
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR: %w", err)
+		}
+
+		return network, nil
+	case addrType:
+		return netip.ParseAddr(value)
+	case prefixType:
+		return netip.ParsePrefix(value)
+	case addrPortType:
+		addrPort, err := netip.ParseAddrPort(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q: %w", value, err)
+		}
+
+		return addrPort, nil
+	}
+
+	return data, nil
+}
+
+// regexpHook compiles strings into regexp.Regexp and *regexp.Regexp fields.
+func regexpHook(_ reflect.Type, to reflect.Type, data any) (any, error) {
+	value, ok := data.(string)
+	if !ok || (to != regexpType && to != reflect.PointerTo(regexpType)) {
+		return data, nil
+	}
+
+	return regexp.Compile(value)
+}
+
+// logLevelHook decodes "debug", "info", "warn" and "error" into slog.Level
+// fields, case-insensitive and with offsets like "debug-4" of slog.
+func logLevelHook(_ reflect.Type, to reflect.Type, data any) (any, error) {
+	value, ok := data.(string)
+	if !ok || to != levelType {
+		return data, nil
+	}
+
+	value = strings.TrimSpace(value)
+	if strings.EqualFold(value, "warning") {
+		value = "warn"
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q, expected debug, info, warn or error", value)
+	}
+
+	return level, nil
+}
+
+// textUnmarshalerHook decodes strings into fields of types implementing
+// encoding.TextUnmarshaler, e.g. uuid.UUID or custom enums.
+func textUnmarshalerHook(_ reflect.Type, to reflect.Type, data any) (any, error) {
+	value, ok := data.(string)
+	if !ok {
+		return data, nil
+	}
+
+	// pointer fields like *Level are allocated and set to the decoded value
+	pointer := to.Kind() == reflect.Pointer
+	if pointer {
+		to = to.Elem()
+	}
+
+	target := reflect.New(to)
+
+	unmarshaler, ok := target.Interface().(encoding.TextUnmarshaler)
+	if !ok {
+		return data, nil
+	}
+
+	if err := unmarshaler.UnmarshalText([]byte(value)); err != nil {
+		return nil, err
+	}
+
+	if pointer {
+		return target.Interface(), nil
+	}
+
+	return target.Elem().Interface(), nil
+}
+
+// timeHook decodes strings in RFC 3339 or one of the layouts into time.Time
+// fields. With a location, times without a time zone are in the location and
+// all times are converted to it.
+func timeHook(layouts []string, location *time.Location) mapstructure.DecodeHookFuncType {
+	layouts = append([]string{time.RFC3339}, layouts...)
+
+	return func(_ reflect.Type, to reflect.Type, data any) (any, error) {
+		if to != timeType {
+			return data, nil
+		}
+
+		switch value := data.(type) {
+		case string:
+			for _, layout := range layouts {
+				parsed, err := time.ParseInLocation(layout, strings.TrimSpace(value), cmp.Or(location, time.UTC))
+				if err == nil {
+					return inLocation(parsed, location), nil
+				}
+			}
+
+			return nil, fmt.Errorf("invalid time %q, expected one of the layouts %q", value, layouts)
+		case time.Time:
+			return inLocation(value, location), nil
+		}
+
+		return data, nil
+	}
+}
+
+// inLocation converts t to the location, if any.
+func inLocation(t time.Time, location *time.Location) time.Time {
+	if location == nil {
+		return t
+	}
+
+	return t.In(location)
+}
+
+// tomlLocalTimeHook converts the TOML local date, date-time and time values to
+// time.Time in the location or to strings, instead of silently decoding them
+// into zero values.
+func tomlLocalTimeHook(location *time.Location) mapstructure.DecodeHookFuncType {
+	return func(_ reflect.Type, to reflect.Type, data any) (any, error) {
+		switch value := data.(type) {
+		case toml.LocalDate:
+			if to == timeType {
+				return value.AsTime(location), nil
+			}
+
+			if to.Kind() == reflect.String {
+				return value.String(), nil
+			}
+		case toml.LocalDateTime:
+			if to == timeType {
+				return value.AsTime(location), nil
+			}
+
+			if to.Kind() == reflect.String {
+				return value.String(), nil
+			}
+		case toml.LocalTime:
+			if to.Kind() == reflect.String {
+				return value.String(), nil
+			}
+		}
+
+		return data, nil
+	}
+}
@@ -0,0 +1,34 @@
+package config
+
+// Decrypter decrypts encrypted config data, e.g. of the sops module
+// schneider.vip/config/sops.
+type Decrypter interface {
+	// Decrypt returns the plain data of the format, e.g. "yaml". Data which is
+	// not encrypted is returned as is.
+	Decrypt(data []byte, configType string) ([]byte, error)
+}
+
+// DecrypterFunc is a function implementing Decrypter.
+type DecrypterFunc func(data []byte, configType string) ([]byte, error)
+
+// Decrypt calls f.
+func (f DecrypterFunc) Decrypt(data []byte, configType string) ([]byte, error) {
+	return f(data, configType)
+}
+
+// WithDecrypter is an option to decrypt config files, readers and sources
+// before parsing. Decryption schemes with large dependencies, like SOPS with
+// the KMS SDKs, are added this way, so only applications using them depend on
+// them.
+func WithDecrypter[T any](decrypter Decrypter) Option[T] {
+	return func(cl *loader[T]) {
+		cl.decrypters = append(cl.decrypters, decrypter)
+
+		// files given by earlier options are read again decrypted
+		if cl.hasConfigFiles() {
+			if err := cl.readConfig(); err != nil {
+				cl.logger.Error("Failed to read config from file", "error", err)
+			}
+		}
+	}
+}
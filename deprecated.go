@@ -0,0 +1,66 @@
+package config
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// deprecatedTag is the struct tag of deprecated fields, its value is a hint
+// for the migration, e.g. deprecated:"use databaseConfig.host".
+const deprecatedTag = "deprecated"
+
+// WithDeprecatedKeys is an option to warn when the keys are set by a config
+// file or source, like for fields with a deprecated tag. Nested keys are
+// separated by "_", e.g. "databaseconfig_hostname".
+func WithDeprecatedKeys[T any](keys ...string) Option[T] {
+	return func(cl *loader[T]) {
+		if cl.deprecatedKeys == nil {
+			cl.deprecatedKeys = make(map[string]string)
+		}
+
+		for _, key := range keys {
+			cl.deprecatedKeys[strings.ToLower(key)] = ""
+		}
+	}
+}
+
+// warnDeprecated logs a warning for every deprecated key, which is set by a
+// config file or source.
+func (c *loader[T]) warnDeprecated() {
+	deprecated := make(map[string]string)
+
+	_ = walkFields(reflect.TypeFor[T](), strings.ToLower(c.subSection), func(key string, field reflect.StructField) error {
+		if hint, ok := field.Tag.Lookup(deprecatedTag); ok {
+			deprecated[key] = hint
+		}
+
+		return nil
+	})
+
+	for key, hint := range c.deprecatedKeys {
+		if _, ok := deprecated[key]; !ok {
+			deprecated[key] = hint
+		}
+	}
+
+	origins := slices.Sorted(maps.Keys(c.origins))
+
+	for _, key := range slices.Sorted(maps.Keys(deprecated)) {
+		for _, origin := range origins {
+			if origin != key && !strings.HasPrefix(origin, key+keyDelimiter) {
+				continue
+			}
+
+			args := []any{"key", key, "source", c.origins[origin].String()}
+			if hint := deprecated[key]; hint != "" {
+				args = append(args, "hint", hint)
+			}
+
+			c.logger.Info("Deprecated config key is set", args...)
+
+			break
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Change is the change of a config value by a reload.
+type Change struct {
+	// Path is the key of the value, nested with "_" like for viper, e.g.
+	// "databaseconfig_host".
+	Path string
+	// Old is the previous value, nil if the key was added.
+	Old any
+	// New is the current value, nil if the key was removed.
+	New any
+}
+
+// String returns the change like "databaseconfig_port: 5432 -> 6543".
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %v -> %v", c.Path, c.Old, c.New)
+}
+
+// WithOnConfigChange is an option to set a callback, which is called after
+// every successful reload with the previous and the current config and the
+// changed values. The values of secret fields are masked in the diff.
+func WithOnConfigChange[T any](callback func(previous, current T, diff []Change)) Option[T] {
+	return func(cl *loader[T]) {
+		cl.onConfigChange = callback
+	}
+}
+
+// LastDiff returns the changed values of the last successful reload, nil if
+// the config was not reloaded yet. The values of secret fields are masked.
+func (c *loader[T]) LastDiff() []Change {
+	diff := c.lastDiff.Load()
+	if diff == nil {
+		return nil
+	}
+
+	return slices.Clone(*diff)
+}
+
+// settings returns the settings of the config, or of the section if set.
+func (c *loader[T]) settings() (map[string]any, error) {
+	if c.subSection == "" {
+		return c.allSettings(), nil
+	}
+
+	sub := c.sub(c.subSection)
+	if sub == nil {
+		return nil, fmt.Errorf("%w: \"%s\"", errSectionNotFound, c.subSection)
+	}
+
+	return sub.AllSettings(), nil
+}
+
+// diffSettings returns the changed values between the settings ordered by
+// path, the values of secret fields are masked.
+func (c *loader[T]) diffSettings(previous, current map[string]any) []Change {
+	values := make(map[string][2]any)
+
+	flattenSettings(previous, "", func(key string, value any) {
+		values[key] = [2]any{value, nil}
+	})
+	flattenSettings(current, "", func(key string, value any) {
+		values[key] = [2]any{values[key][0], value}
+	})
+
+	secrets := c.secretKeys()
+	if prefix := strings.ToLower(c.subSection); prefix != "" {
+		for i, key := range secrets {
+			secrets[i] = strings.TrimPrefix(key, prefix+keyDelimiter)
+		}
+	}
+
+	var diff []Change
+
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		old, value := values[key][0], values[key][1]
+		if reflect.DeepEqual(old, value) {
+			continue
+		}
+
+		if isSecretKey(key, secrets) {
+			old, value = redactValue(old), redactValue(value)
+		}
+
+		diff = append(diff, Change{Path: key, Old: old, New: value})
+	}
+
+	return diff
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Docs returns a Markdown table of the keys of the config of T with their
+// type, the default tag, the environment variable and the doc tag. Keys are
+// the paths of the fields in the config file, the environment variables are
+// the env tags or the names of AutomaticEnv without a prefix.
+func Docs[T any]() string {
+	var b strings.Builder
+
+	b.WriteString("| Key | Type | Default | Environment Variable | Description |\n")
+	b.WriteString("| --- | --- | --- | --- | --- |\n")
+
+	writeDocs(&b, describeFields(reflect.TypeFor[T](), ""), "")
+
+	return b.String()
+}
+
+// writeDocs writes a row of every field, the fields of sections are nested
+// under the path.
+func writeDocs(b *strings.Builder, fields []fieldInfo, path string) {
+	for _, f := range fields {
+		name := f.name
+		if path != "" {
+			name = path + "." + f.name
+		}
+
+		if f.section {
+			writeDocs(b, f.fields, name)
+
+			continue
+		}
+
+		defaultValue, ok := f.defaultValue()
+		if ok {
+			defaultValue = "`" + defaultValue + "`"
+		}
+
+		doc := f.doc()
+		if f.required {
+			doc = strings.TrimSpace(doc + " (required)")
+		}
+
+		fmt.Fprintf(b, "| `%s` | %s | %s | %s | %s |\n", name, escapeMarkdown(typeName(f.typ)),
+			escapeMarkdown(defaultValue), escapeMarkdown(envName(f)), escapeMarkdown(doc))
+	}
+}
+
+// envName returns the environment variable of the field, the env tag or the
+// name of AutomaticEnv, empty for noenv fields.
+func envName(f fieldInfo) string {
+	if isNoEnv(f.field) {
+		return ""
+	}
+
+	if name := f.field.Tag.Get(envTag); name != "" {
+		return name
+	}
+
+	return strings.ToUpper(f.key)
+}
+
+// typeName returns the name of the type of a config value.
+func typeName(t reflect.Type) string {
+	switch {
+	case t == durationType:
+		return "duration"
+	case t == timeType:
+		return "time"
+	}
+
+	return t.String()
+}
+
+// escapeMarkdown escapes the text for a cell of a Markdown table.
+func escapeMarkdown(text string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(text)
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"io"
+)
+
+// Dump writes the effective config in the format, e.g. "yaml" or "json", with
+// defaults, environment variables and all sources merged and the values of
+// secret fields masked. With WithSubSection only the section is written.
+func (c *loader[T]) Dump(w io.Writer, format string) error {
+	settings, err := c.settings()
+	if err != nil {
+		return err
+	}
+
+	c.redactSettings(settings, c.subSection)
+
+	data, err := encodeConfig(settings, format)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	_, err = w.Write(data)
+
+	return err
+}
+
+// Export returns the effective config encoded in the format, e.g. to convert
+// a YAML config to TOML. Like Dump it contains defaults, environment variables
+// and all sources merged, but the values of secret fields are not masked.
+func (c *loader[T]) Export(format string) ([]byte, error) {
+	settings, err := c.settings()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := encodeConfig(settings, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,221 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/subosito/gotenv"
+)
+
+// envTag is the struct tag to bind a field to an environment variable,
+// e.g. `env:"DB_HOST"`.
+const envTag = "env"
+
+// noEnvTag is the struct tag to forbid environment overrides of a field, e.g.
+// `noenv:"true"` for a setting which must only come from the config.
+const noEnvTag = "noenv"
+
+// bindEnvTags binds every field with an env tag to its environment variable,
+// so the value is used even if the key is missing in the config file.
+// The keys of the fields are prefixed with the section.
+func bindEnvTags(v *viper.Viper, t reflect.Type, section string) error {
+	return walkFields(t, strings.ToLower(section), func(key string, field reflect.StructField) error {
+		envName := field.Tag.Get(envTag)
+		if envName == "" || isNoEnv(field) {
+			return nil
+		}
+
+		return v.BindEnv(key, envName)
+	})
+}
+
+// WithDotEnv is an option to load environment variables from dotenv files,
+// defaults to ".env" if no file is given. The files are read again on every
+// reload and the process environment is not modified. Variables which are set
+// in the environment take precedence over the dotenv files, which take
+// precedence over the sources and the config file. Earlier files take
+// precedence over later ones.
+func WithDotEnv[T any](filenames ...string) Option[T] {
+	return func(cl *loader[T]) {
+		if len(filenames) == 0 {
+			filenames = []string{".env"}
+		}
+
+		cl.dotEnvFiles = append(cl.dotEnvFiles, filenames...)
+	}
+}
+
+// readDotEnv reads the variables of the dotenv files, files which cannot be
+// read are logged and skipped.
+func (c *loader[T]) readDotEnv() map[string]string {
+	dotEnv := make(map[string]string)
+
+	for _, filename := range c.dotEnvFiles {
+		env, err := gotenv.Read(filename)
+		if err != nil {
+			c.logger.Error("Failed to read dotenv file", "error", err)
+
+			continue
+		}
+
+		for name, value := range env {
+			if _, ok := dotEnv[name]; !ok {
+				dotEnv[name] = value
+			}
+		}
+	}
+
+	return dotEnv
+}
+
+// mergeDotEnv reads the dotenv files and merges the variables of the fields,
+// which are not set in the environment, on top of the config. Variables set
+// in the environment are read by viper.
+func (c *loader[T]) mergeDotEnv() error {
+	c.dotEnv = nil
+	if len(c.dotEnvFiles) == 0 {
+		return nil
+	}
+
+	c.dotEnv = c.readDotEnv()
+	envNames := c.envTagNames()
+
+	for _, f := range describeLeaves(describeFields(reflect.TypeFor[T](), strings.ToLower(c.subSection))) {
+		if isNoEnv(f.field) {
+			continue
+		}
+
+		for _, name := range []string{envNames[f.key], c.automaticEnvName(f.key)} {
+			if name == "" {
+				continue
+			}
+
+			if value, ok := os.LookupEnv(name); ok && value != "" {
+				break
+			}
+
+			value, ok := c.dotEnv[name]
+			if !ok {
+				continue
+			}
+
+			settings := make(map[string]any)
+			setPath(settings, strings.Split(f.key, keyDelimiter), value)
+			c.setOrigins(settings, SourceInfo{Kind: KindEnv, Name: name})
+
+			if err := c.viper.MergeConfigMap(settings); err != nil {
+				return err
+			}
+
+			break
+		}
+	}
+
+	return nil
+}
+
+// getEnv returns the value of the environment variable, or of the dotenv
+// files if it is not set in the environment.
+func (c *loader[T]) getEnv(name string) string {
+	value, _ := c.lookupEnv(name)
+
+	return value
+}
+
+// lookupEnv looks up the environment variable, or the variable of the dotenv
+// files if it is not set in the environment.
+func (c *loader[T]) lookupEnv(name string) (string, bool) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, true
+	}
+
+	value, ok := c.dotEnv[name]
+
+	return value, ok
+}
+
+// isNoEnv reports whether the field is tagged with noenv:"true".
+func isNoEnv(field reflect.StructField) bool {
+	noEnv, _ := strconv.ParseBool(field.Tag.Get(noEnvTag))
+
+	return noEnv
+}
+
+// envKeyReplacer maps config keys to environment variable names with the
+// replacer of WithEnvKeyReplacer, the keys of noenv fields map to no variable.
+type envKeyReplacer struct {
+	replacer *strings.Replacer
+	noEnv    map[string]bool // the names of the noenv fields before replacing
+}
+
+// Replace returns the name of the environment variable of the key, or an empty
+// name which is never set.
+func (r *envKeyReplacer) Replace(key string) string {
+	if r.noEnv[key] {
+		return ""
+	}
+
+	if r.replacer != nil {
+		return r.replacer.Replace(key)
+	}
+
+	return key
+}
+
+// setNoEnvFields collects the environment variable names of the noenv fields
+// like viper derives them from the keys.
+func (c *loader[T]) setNoEnvFields() {
+	if c.envReplacer == nil {
+		return
+	}
+
+	noEnv := make(map[string]bool)
+
+	_ = walkFields(reflect.TypeFor[T](), strings.ToLower(c.subSection), func(key string, field reflect.StructField) error {
+		if isNoEnv(field) {
+			if prefix := c.viper.GetEnvPrefix(); prefix != "" {
+				key = prefix + "_" + key
+			}
+
+			noEnv[strings.ToUpper(key)] = true
+		}
+
+		return nil
+	})
+
+	c.envReplacer.noEnv = noEnv
+}
+
+// EnvVar is an environment variable, which is consulted for a config key.
+type EnvVar struct {
+	Name string // name of the environment variable
+	Key  string // key nested with "_" like for Provenance, e.g. "databaseconfig_host"
+}
+
+// EnvVars returns the environment variables, which are consulted for the fields
+// of the config in the order of the fields: the env tags and the names of
+// AutomaticEnv with the prefix and the key replacer. Fields with a noenv tag
+// have none.
+func (c *loader[T]) EnvVars() []EnvVar {
+	var envVars []EnvVar
+
+	for _, f := range describeLeaves(describeFields(reflect.TypeFor[T](), strings.ToLower(c.subSection))) {
+		if isNoEnv(f.field) {
+			continue
+		}
+
+		name := f.field.Tag.Get(envTag)
+		if name != "" {
+			envVars = append(envVars, EnvVar{Name: name, Key: f.key})
+		}
+
+		if automatic := c.automaticEnvName(f.key); automatic != "" && automatic != name {
+			envVars = append(envVars, EnvVar{Name: automatic, Key: f.key})
+		}
+	}
+
+	return envVars
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// WithEtcd is an option to load the config from an etcd v3 key. The key is
+// watched by StartWatcher and every change triggers a reload. etcd is accessed
+// via its JSON gateway, the endpoints are tried in order.
+// The format is derived from the key extension, e.g. "/myapp/config.yml",
+// unless WithFormat is used. WithBasicAuth authenticates with etcd user/password.
+func WithEtcd[T any](endpoints []string, key string, opts ...RemoteOption) Option[T] {
+	return func(cl *loader[T]) {
+		cl.addSource(EtcdSource(endpoints, key, opts...))
+	}
+}
+
+// EtcdSource returns the source of WithEtcd, e.g. for WithSources.
+func EtcdSource(endpoints []string, key string, opts ...RemoteOption) Source {
+	return &etcdSource{
+		endpoints: endpoints,
+		key:       key,
+		opts:      newRemoteOptions(key, opts),
+	}
+}
+
+var errKeyNotFound = errors.New("key not found")
+
+// etcdSource reads and watches a key of etcd.
+type etcdSource struct {
+	endpoints []string
+	key       string
+	opts      remoteOptions
+}
+
+// Read returns the value of the key.
+func (s *etcdSource) Read() ([]byte, string, error) {
+	var response struct {
+		Kvs []struct {
+			Value []byte `json:"value"`
+		} `json:"kvs"`
+	}
+
+	ctx, cancel := s.opts.readContext()
+	defer cancel()
+
+	body, err := s.post(ctx, "/v3/kv/range", map[string]any{"key": []byte(s.key)})
+	if err != nil {
+		return nil, "", err
+	}
+	defer body.Close()
+
+	if err := json.NewDecoder(body).Decode(&response); err != nil {
+		return nil, "", fmt.Errorf("failed to decode etcd response: %w", err)
+	}
+
+	if len(response.Kvs) == 0 {
+		return nil, "", fmt.Errorf("%w in etcd: %s", errKeyNotFound, s.key)
+	}
+
+	return response.Kvs[0].Value, s.opts.configType, nil
+}
+
+// Watch streams the watch events of the key. A broken watch is retried in the
+// poll interval and a change is sent, so updates missed meanwhile are read.
+func (s *etcdSource) Watch(ctx context.Context) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+
+	go func() {
+		defer close(changes)
+
+		for {
+			_ = s.watch(ctx, changes)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(s.opts.pollInterval):
+				notify(changes)
+			}
+		}
+	}()
+
+	return changes
+}
+
+// watch sends a change for every watch response with events, until the
+// stream breaks.
+func (s *etcdSource) watch(ctx context.Context, changes chan<- struct{}) error {
+	body, err := s.post(ctx, "/v3/watch", map[string]any{
+		"create_request": map[string]any{"key": []byte(s.key)},
+	})
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	decoder := json.NewDecoder(body)
+
+	for {
+		var response struct {
+			Result struct {
+				Events []json.RawMessage `json:"events"`
+			} `json:"result"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+
+		if err := decoder.Decode(&response); err != nil {
+			return err
+		}
+
+		if response.Error != nil {
+			return fmt.Errorf("etcd watch failed: %s", response.Error.Message)
+		}
+
+		if len(response.Result.Events) > 0 {
+			notify(changes)
+		}
+	}
+}
+
+// post sends the request to the first endpoint which responds successfully
+// and returns the response body.
+func (s *etcdSource) post(ctx context.Context, path string, request any) (io.ReadCloser, error) {
+	var errs []error
+
+	for _, endpoint := range s.endpoints {
+		body, err := s.postEndpoint(ctx, strings.TrimSuffix(endpoint, "/"), path, request)
+		if err == nil {
+			return body, nil
+		}
+
+		errs = append(errs, err)
+	}
+
+	return nil, fmt.Errorf("etcd request %s failed: %w", path, errors.Join(errs...))
+}
+
+// postEndpoint sends the request to an endpoint, authenticating first if
+// credentials are set.
+func (s *etcdSource) postEndpoint(ctx context.Context, endpoint, path string, request any) (io.ReadCloser, error) {
+	var token string
+
+	if s.opts.username != "" {
+		var response struct {
+			Token string `json:"token"`
+		}
+
+		body, err := postJSON(ctx, s.opts.httpClient, endpoint+"/v3/auth/authenticate", "", map[string]any{
+			"name":     s.opts.username,
+			"password": s.opts.password,
+		})
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+
+		if err := json.NewDecoder(body).Decode(&response); err != nil {
+			return nil, fmt.Errorf("failed to decode etcd auth response: %w", err)
+		}
+
+		token = response.Token
+	}
+
+	return postJSON(ctx, s.opts.httpClient, endpoint+path, token, request)
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"expvar"
+	"time"
+)
+
+// WithExpvar is an option to publish the metadata of the config under the name
+// with expvar, e.g. "config" on /debug/vars: the config files, the format, the
+// time of the last reload and of the last successful parse, the checksum of the
+// effective config and the number of reloads and failed reloads.
+func WithExpvar[T any](name string) Option[T] {
+	return func(cl *loader[T]) {
+		if expvar.Get(name) != nil {
+			cl.logger.Error("Failed to publish config metadata, the expvar name is in use", "name", name)
+
+			return
+		}
+
+		expvar.Publish(name, expvar.Func(func() any { return cl.metadata() }))
+	}
+}
+
+// metadata returns the metadata of the config for expvar and the admin
+// handler.
+func (c *loader[T]) metadata() map[string]any {
+	stats := c.stats.snapshot()
+
+	var files []string
+
+	for _, file := range c.watchedFiles() {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+
+	format := c.configType
+	if format == "" && len(files) > 0 {
+		format = c.fileType(files[0])
+	}
+
+	return map[string]any{
+		"files":           files,
+		"format":          format,
+		"last_reload":     formatTime(stats.lastReload),
+		"last_success":    formatTime(stats.lastSuccess),
+		"checksum":        stats.checksum,
+		"reloads":         stats.reloads,
+		"reload_failures": stats.reloadFailures,
+	}
+}
+
+// formatTime formats the time in RFC 3339, empty for the zero time.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,185 @@
+package config
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// walkFields calls fn for every exported field of the struct type t and its
+// nested structs, with the config key of the field. Keys are derived like
+// viper does from the mapstructure tag or the field name.
+func walkFields(t reflect.Type, prefix string, fn func(key string, field reflect.StructField) error) error {
+	return walkStruct(t, prefix, fn, map[reflect.Type]bool{})
+}
+
+// walkStruct walks the fields of t, visited prevents endless recursion of
+// self-referencing types.
+func walkStruct(t reflect.Type, prefix string, fn func(key string, field reflect.StructField) error,
+	visited map[reflect.Type]bool,
+) error {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct || visited[t] {
+		return nil
+	}
+
+	visited[t] = true
+	defer delete(visited, t)
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if name == "-" || opts == "remain" {
+			continue
+		}
+
+		if opts == "squash" || (field.Anonymous && name == "") {
+			if err := walkStruct(field.Type, prefix, fn, visited); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		key := strings.ToLower(name)
+		if prefix != "" {
+			key = prefix + keyDelimiter + key
+		}
+
+		if err := fn(key, field); err != nil {
+			return err
+		}
+
+		if err := walkStruct(field.Type, key, fn, visited); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// The struct tags documenting fields for generated examples, schemas and docs.
+// They are documentation only, Parse neither applies defaults nor enforces
+// required fields.
+const (
+	docTag      = "doc"      // description of the field
+	defaultTag  = "default"  // documented default value
+	requiredTag = "required" // "true" for fields which must be set
+)
+
+// fieldInfo describes a field of the config for generated examples, schemas
+// and docs.
+type fieldInfo struct {
+	name     string // name of the key in the config, e.g. "databaseConfig"
+	key      string // key nested with "_" like for viper
+	field    reflect.StructField
+	typ      reflect.Type // type of the field without pointers
+	fields   []fieldInfo  // fields of a section
+	section  bool
+	required bool
+}
+
+// doc returns the description of the field.
+func (f fieldInfo) doc() string {
+	return f.field.Tag.Get(docTag)
+}
+
+// defaultValue returns the documented default value of the field.
+func (f fieldInfo) defaultValue() (string, bool) {
+	return f.field.Tag.Lookup(defaultTag)
+}
+
+// describeFields returns the fields of the struct type t like walkFields,
+// nested structs are sections unless they are decoded from strings.
+func describeFields(t reflect.Type, prefix string) []fieldInfo {
+	return describeStruct(t, prefix, map[reflect.Type]bool{})
+}
+
+// describeStruct describes the fields of t, visited prevents endless recursion
+// of self-referencing types.
+func describeStruct(t reflect.Type, prefix string, visited map[reflect.Type]bool) []fieldInfo {
+	t = indirectType(t)
+	if t.Kind() != reflect.Struct || visited[t] {
+		return nil
+	}
+
+	visited[t] = true
+	defer delete(visited, t)
+
+	var fields []fieldInfo
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if name == "-" || opts == "remain" {
+			continue
+		}
+
+		if opts == "squash" || (field.Anonymous && name == "") {
+			fields = append(fields, describeStruct(field.Type, prefix, visited)...)
+
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		key := strings.ToLower(name)
+		if prefix != "" {
+			key = prefix + keyDelimiter + key
+		}
+
+		required, _ := strconv.ParseBool(field.Tag.Get(requiredTag))
+		info := fieldInfo{name: name, key: key, field: field, typ: indirectType(field.Type), required: required}
+
+		if info.typ.Kind() == reflect.Struct && !isTextType(info.typ) {
+			info.section = true
+			info.fields = describeStruct(info.typ, key, visited)
+		}
+
+		fields = append(fields, info)
+	}
+
+	return fields
+}
+
+// indirectType returns the type t points to.
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+// describeLeaves returns the fields which are no sections, nested fields of
+// sections in place of the section.
+func describeLeaves(fields []fieldInfo) []fieldInfo {
+	var leaves []fieldInfo
+
+	for _, f := range fields {
+		if f.section {
+			leaves = append(leaves, describeLeaves(f.fields)...)
+		} else {
+			leaves = append(leaves, f)
+		}
+	}
+
+	return leaves
+}
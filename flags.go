@@ -0,0 +1,230 @@
+package config
+
+import (
+	"encoding"
+	"flag"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// flagTag is the struct tag of the command-line flag of a field, flags are
+// named after the key by default, e.g. "databaseconfig-host".
+const flagTag = "flag"
+
+// WithFlagSet is an option to bind the flags of fs to the config. Flags, which
+// were set on the command line, override environment variables, files and
+// defaults, the values of other flags are defaults. A flag binds to the field
+// with its name in the flag tag, or else to the key of its name with "-" or
+// "." as delimiter, e.g. --databaseconfig-host. Parse fs before New.
+func WithFlagSet[T any](fs *pflag.FlagSet) Option[T] {
+	return func(cl *loader[T]) {
+		cl.flagSets = append(cl.flagSets, fs)
+	}
+}
+
+// WithGoFlagSet is an option to bind the flags of a flag set of the standard
+// library like WithFlagSet. Parse fs before New.
+func WithGoFlagSet[T any](fs *flag.FlagSet) Option[T] {
+	return func(cl *loader[T]) {
+		cl.goFlagSets = append(cl.goFlagSets, fs)
+	}
+}
+
+// bindFlags binds the flags of the flag sets to their keys.
+func (c *loader[T]) bindFlags() error {
+	if len(c.flagSets) == 0 && len(c.goFlagSets) == 0 && len(c.cliContexts) == 0 {
+		return nil
+	}
+
+	keys := c.flagKeys()
+	c.flags = make(map[string]*pflag.Flag)
+	c.bindCLIFlags(keys)
+
+	flagSets := slices.Clone(c.flagSets)
+	for _, fs := range c.goFlagSets {
+		flagSets = append(flagSets, pflagSet(fs))
+	}
+
+	for _, fs := range flagSets {
+		var err error
+
+		fs.VisitAll(func(flag *pflag.Flag) {
+			// the config file is no config key
+			if flag.Name == configFlag {
+				return
+			}
+
+			key, ok := keys[flag.Name]
+			if !ok {
+				key = flagKey(c.subSection, flag.Name)
+			}
+
+			c.flags[key] = flag
+
+			if bindErr := c.viper.BindPFlag(key, flag); bindErr != nil && err == nil {
+				err = bindErr
+			}
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pflagSet converts a flag set of the standard library, the flags which were
+// set on the command line are changed.
+func pflagSet(fs *flag.FlagSet) *pflag.FlagSet {
+	flags := pflag.NewFlagSet(fs.Name(), pflag.ContinueOnError)
+
+	fs.VisitAll(func(goFlag *flag.Flag) {
+		f := pflag.PFlagFromGoFlag(goFlag)
+		f.DefValue = goFlag.DefValue
+		flags.AddFlag(f)
+	})
+
+	fs.Visit(func(goFlag *flag.Flag) {
+		flags.Lookup(goFlag.Name).Changed = true
+	})
+
+	return flags
+}
+
+// RegisterFlags registers a flag for every field of T in fs, which is not
+// defined yet, named like for WithFlagSet and with the doc tag as usage. Bind
+// fs with WithFlagSet after it was parsed:
+//
+//	config.RegisterFlags[Config](pflag.CommandLine)
+//	pflag.Parse()
+//	loader := config.New(config.WithFlagSet[Config](pflag.CommandLine))
+func RegisterFlags[T any](fs *pflag.FlagSet) {
+	var leaves []string
+
+	_ = walkFields(reflect.TypeFor[T](), "", func(key string, field reflect.StructField) error {
+		// the fields of structs decoded from strings are no flags
+		for _, leaf := range leaves {
+			if strings.HasPrefix(key, leaf+keyDelimiter) {
+				return nil
+			}
+		}
+
+		name := flagName("", key, field)
+		if fs.Lookup(name) != nil {
+			return nil
+		}
+
+		if registerFlag(fs, name, field.Type, field.Tag.Get(docTag)) && isStruct(field.Type) {
+			leaves = append(leaves, key)
+		}
+
+		return nil
+	})
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// registerFlag registers a flag of the type, it reports whether the type is
+// supported. Sections, maps except of strings and interfaces have no flag.
+func registerFlag(fs *pflag.FlagSet, name string, t reflect.Type, usage string) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == durationType:
+		fs.Duration(name, 0, usage)
+	case isTextType(t):
+		fs.String(name, "", usage)
+	case t.Kind() == reflect.Bool:
+		fs.Bool(name, false, usage)
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		fs.Int64(name, 0, usage)
+	case t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uint64:
+		fs.Uint64(name, 0, usage)
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		fs.Float64(name, 0, usage)
+	case t.Kind() == reflect.String:
+		fs.String(name, "", usage)
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		fs.StringSlice(name, nil, usage)
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.String:
+		fs.StringToString(name, nil, usage)
+	default:
+		return false
+	}
+
+	return true
+}
+
+// isTextType reports whether values of t are decoded from strings, like
+// types implementing encoding.TextUnmarshaler, URLs or IP addresses.
+func isTextType(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(textUnmarshalerType) ||
+		slices.Contains([]reflect.Type{bytesType, urlType, ipType, ipNetType, regexpType}, t)
+}
+
+// isStruct reports whether t is a struct or a pointer to a struct.
+func isStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Struct
+}
+
+// flagKeys returns the keys of the fields by flag name.
+func (c *loader[T]) flagKeys() map[string]string {
+	keys := make(map[string]string)
+	section := strings.ToLower(c.subSection)
+
+	_ = walkFields(reflect.TypeFor[T](), section, func(key string, field reflect.StructField) error {
+		keys[flagName(section, key, field)] = key
+
+		return nil
+	})
+
+	return keys
+}
+
+// flagName returns the name of the flag of the field with the key, relative
+// to the section.
+func flagName(section, key string, field reflect.StructField) string {
+	if name := field.Tag.Get(flagTag); name != "" {
+		return name
+	}
+
+	if section != "" {
+		key = strings.TrimPrefix(key, section+keyDelimiter)
+	}
+
+	return strings.ReplaceAll(key, keyDelimiter, "-")
+}
+
+// flagKey returns the key of a flag name, which is not the name of a field.
+func flagKey(section, name string) string {
+	key := strings.NewReplacer("-", keyDelimiter, ".", keyDelimiter).Replace(strings.ToLower(name))
+	if section != "" {
+		key = strings.ToLower(section) + keyDelimiter + key
+	}
+
+	return key
+}
+
+// changedFlag returns the name of the flag, which was set on the command line
+// for the key.
+func (c *loader[T]) changedFlag(key string) (string, bool) {
+	if name, ok := c.cliFlags[key]; ok {
+		return name, true
+	}
+
+	if flag, ok := c.flags[key]; ok && flag.Changed {
+		return "--" + flag.Name, true
+	}
+
+	return "", false
+}
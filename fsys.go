@@ -0,0 +1,49 @@
+package config
+
+import (
+	"context"
+	"io/fs"
+)
+
+// WithFS is an option to load the config file at path of the file system fsys,
+// e.g. an embed.FS. The format is derived from the file extension like for
+// config files.
+//
+//	//go:embed config.yml
+//	var configFS embed.FS
+//
+//	config.WithFS[GlobalConfig](configFS, "config.yml")
+func WithFS[T any](fsys fs.FS, path string) Option[T] {
+	return func(cl *loader[T]) {
+		cl.addSource(&fsSource{fsys: fsys, path: path})
+	}
+}
+
+// fsSource reads a config file of a file system.
+type fsSource struct {
+	fsys fs.FS
+	path string
+}
+
+// Read returns the content of the file.
+func (s *fsSource) Read() ([]byte, string, error) {
+	data, err := fs.ReadFile(s.fsys, s.path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, configTypeFromPath(s.path), nil
+}
+
+// String returns the path of the file.
+func (s *fsSource) String() string {
+	return s.path
+}
+
+// Watch returns a closed channel, the file system is not watched.
+func (s *fsSource) Watch(context.Context) <-chan struct{} {
+	changes := make(chan struct{})
+	close(changes)
+
+	return changes
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"schneider.vip/config/internal/gcp"
+)
+
+const gcpSecretManagerEndpoint = "https://secretmanager.googleapis.com"
+
+// WithGCPSecretManager is an option to set config keys from secrets of GCP
+// Secret Manager. secrets maps config keys to secret versions, e.g.
+// "databaseConfig_password" to "projects/myproject/secrets/db-password/versions/3".
+// A plain secret name like "db-password" refers to the latest version in the
+// project of GOOGLE_CLOUD_PROJECT or the credentials. Credentials are taken
+// from the Application Default Credentials, StartWatcher polls the secrets in
+// the poll interval.
+func WithGCPSecretManager[T any](secrets map[string]string, opts ...RemoteOption) Option[T] {
+	return func(cl *loader[T]) {
+		o := newRemoteOptions("", opts)
+		if o.endpoint == "" {
+			o.endpoint = gcpSecretManagerEndpoint
+		}
+
+		cl.addSource(&gcpSecretManagerSource{
+			secrets: secrets,
+			opts:    o,
+			tokens:  gcp.NewTokenProvider(o.httpClient),
+		})
+	}
+}
+
+// gcpSecretManagerSource reads secrets of GCP Secret Manager.
+type gcpSecretManagerSource struct {
+	secrets map[string]string
+	opts    remoteOptions
+	tokens  *gcp.TokenProvider
+}
+
+// Read returns the secrets at their config keys as JSON.
+func (s *gcpSecretManagerSource) Read() ([]byte, string, error) {
+	ctx, cancel := s.opts.readContext()
+	defer cancel()
+
+	settings := make(map[string]any)
+
+	for key, secret := range s.secrets {
+		value, err := s.accessSecret(ctx, secret)
+		if err != nil {
+			return nil, "", err
+		}
+
+		setPath(settings, strings.Split(strings.ToLower(key), keyDelimiter), value)
+	}
+
+	data, err := json.Marshal(nestSection(s.opts.section, settings))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, "json", nil
+}
+
+// Watch polls the secrets for changes.
+func (s *gcpSecretManagerSource) Watch(ctx context.Context) <-chan struct{} {
+	return pollChanges(ctx, s.opts.pollInterval, func() ([]byte, error) {
+		data, _, err := s.Read()
+
+		return data, err
+	})
+}
+
+// accessSecret returns the payload of the secret version.
+func (s *gcpSecretManagerSource) accessSecret(ctx context.Context, secret string) (string, error) {
+	if !strings.HasPrefix(secret, "projects/") {
+		project, err := s.tokens.ProjectID(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		secret = fmt.Sprintf("projects/%s/secrets/%s/versions/latest", project, secret)
+	}
+
+	token, err := s.tokens.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.opts.endpoint+"/v1/"+secret+":access", nil)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := doRequest(s.opts.httpClient, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to access secret %s: %w", secret, err)
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		Payload struct {
+			Data []byte `json:"data"`
+		} `json:"payload"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", fmt.Errorf("failed to decode secret %s: %w", secret, err)
+	}
+
+	return string(response.Payload.Data), nil
+}
@@ -0,0 +1,283 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// GenerateExample returns an example config of T in the format. YAML and TOML
+// examples contain the doc tags as comments and mark required fields, other
+// formats like JSON have no comments. The values are the default tags or the
+// zero values of the fields. The tags are not applied when the config is parsed.
+func GenerateExample[T any](format string) ([]byte, error) {
+	fields := describeFields(reflect.TypeFor[T](), "")
+
+	var buf bytes.Buffer
+
+	switch strings.ToLower(format) {
+	case "yaml", "yml":
+		if err := writeYAMLExample(&buf, fields, ""); err != nil {
+			return nil, err
+		}
+	case "toml":
+		if err := writeTOMLExample(&buf, fields, ""); err != nil {
+			return nil, err
+		}
+	case "json":
+		data, err := json.MarshalIndent(exampleSettings(fields), "", "  ")
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(append(data, '\n'))
+	default:
+		return encodeConfig(exampleSettings(fields), format)
+	}
+
+	return buf.Bytes(), nil
+}
+
+var errFileExists = errors.New("config file exists")
+
+// Init writes the example config of GenerateExample to the file, e.g. for an
+// init command, in the format of the extension of the file. An existing file
+// is not overwritten. The file is created with 0600, as it may hold secrets
+// once it is filled in.
+func Init[T any](path string) error {
+	data, err := GenerateExample[T](strings.TrimPrefix(configExt(path), "."))
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%w: %s", errFileExists, path)
+	} else if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+
+		return err
+	}
+
+	return file.Close()
+}
+
+// writeYAMLExample writes the fields as YAML with comments.
+func writeYAMLExample(buf *bytes.Buffer, fields []fieldInfo, indent string) error {
+	for _, f := range fields {
+		writeComment(buf, f, indent)
+
+		if f.section {
+			if len(f.fields) == 0 {
+				fmt.Fprintf(buf, "%s%s: {}\n", indent, f.name)
+
+				continue
+			}
+
+			fmt.Fprintf(buf, "%s%s:\n", indent, f.name)
+
+			if err := writeYAMLExample(buf, f.fields, indent+"  "); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		// the value is encoded under its key, so multi-line values like
+		// lists and literal strings are indented as a block
+		var value bytes.Buffer
+
+		enc := yaml.NewEncoder(&value)
+		enc.SetIndent(2)
+
+		if err := enc.Encode(map[string]any{f.name: exampleValue(f)}); err != nil {
+			return err
+		}
+
+		if err := enc.Close(); err != nil {
+			return err
+		}
+
+		for _, line := range strings.SplitAfter(value.String(), "\n") {
+			if line != "" {
+				buf.WriteString(indent + line)
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeTOMLExample writes the fields as TOML with comments, the values of the
+// table first, then the sections as nested tables.
+func writeTOMLExample(buf *bytes.Buffer, fields []fieldInfo, table string) error {
+	for _, f := range fields {
+		if f.section {
+			continue
+		}
+
+		writeComment(buf, f, "")
+
+		switch value := exampleValue(f); value.(type) {
+		case nil:
+			fmt.Fprintf(buf, "# %s =\n", f.name)
+		case map[string]any:
+			fmt.Fprintf(buf, "%s = {}\n", f.name)
+		default:
+			line, err := toml.Marshal(map[string]any{f.name: value})
+			if err != nil {
+				return err
+			}
+
+			buf.Write(line)
+		}
+	}
+
+	for _, f := range fields {
+		if !f.section {
+			continue
+		}
+
+		name := f.name
+		if table != "" {
+			name = table + "." + f.name
+		}
+
+		if buf.Len() > 0 {
+			buf.WriteByte('\n')
+		}
+
+		writeComment(buf, f, "")
+		fmt.Fprintf(buf, "[%s]\n", name)
+
+		if err := writeTOMLExample(buf, f.fields, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeComment writes the doc tag of the field as comment and marks required
+// fields.
+func writeComment(buf *bytes.Buffer, f fieldInfo, indent string) {
+	doc := f.doc()
+	if f.required {
+		doc = strings.TrimSpace(doc + " (required)")
+	}
+
+	if doc == "" {
+		return
+	}
+
+	for _, line := range strings.Split(doc, "\n") {
+		fmt.Fprintf(buf, "%s# %s\n", indent, line)
+	}
+}
+
+// exampleSettings returns the example values of the fields nested by section.
+func exampleSettings(fields []fieldInfo) map[string]any {
+	settings := make(map[string]any)
+
+	for _, f := range fields {
+		if f.section {
+			settings[f.name] = exampleSettings(f.fields)
+		} else {
+			settings[f.name] = exampleValue(f)
+		}
+	}
+
+	return settings
+}
+
+// exampleValue returns the default value of the field, or its zero value.
+func exampleValue(f fieldInfo) any {
+	if value, ok := f.defaultValue(); ok {
+		return typedValue(f.typ, value)
+	}
+
+	return zeroValue(f.typ)
+}
+
+// typedValue converts the value of a tag to the kind of t, lists are comma
+// separated. Values which cannot be converted stay strings.
+func typedValue(t reflect.Type, value string) any {
+	if t == durationType || isTextType(t) {
+		return value
+	}
+
+	var (
+		typed any
+		err   error
+	)
+
+	switch t.Kind() {
+	case reflect.Bool:
+		typed, err = strconv.ParseBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		typed, err = strconv.ParseInt(value, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		typed, err = strconv.ParseUint(value, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		typed, err = strconv.ParseFloat(value, 64)
+	case reflect.Slice, reflect.Array:
+		items := []any{}
+
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, typedValue(indirectType(t.Elem()), item))
+			}
+		}
+
+		return items
+	default:
+		return value
+	}
+
+	if err != nil {
+		return value
+	}
+
+	return typed
+}
+
+// zeroValue returns the zero value of t in a config, nil for types without.
+func zeroValue(t reflect.Type) any {
+	switch {
+	case t == durationType:
+		return "0s"
+	case isTextType(t):
+		return ""
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return false
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return 0
+	case reflect.Float32, reflect.Float64:
+		return 0.0
+	case reflect.String:
+		return ""
+	case reflect.Slice, reflect.Array:
+		return []any{}
+	case reflect.Map:
+		return map[string]any{}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// gitTimeout is the timeout of a git fetch.
+const gitTimeout = 2 * time.Minute
+
+// WithGitSource is an option to load the config from a file of a git
+// repository at ref, a branch, tag or commit, an empty ref selects the default
+// branch. StartWatcher fetches the ref in the poll interval and reloads if the
+// file changed with a new commit. The git command must be installed, the
+// repository is fetched shallow into a temporary directory, which is removed
+// after every read.
+// The format is derived from the file extension unless WithFormat is used.
+// WithBasicAuth and WithBearerToken authenticate HTTPS repositories, SSH
+// repositories use the SSH agent and config of the user.
+func WithGitSource[T any](repoURL, ref, path string, opts ...RemoteOption) Option[T] {
+	return func(cl *loader[T]) {
+		if ref == "" {
+			ref = "HEAD"
+		}
+
+		cl.addSource(&gitSource{
+			url:  repoURL,
+			ref:  ref,
+			path: strings.TrimPrefix(path, "/"),
+			opts: newRemoteOptions(path, opts),
+		})
+	}
+}
+
+// gitSource reads a file of a git repository.
+type gitSource struct {
+	url  string
+	ref  string
+	path string
+	opts remoteOptions
+}
+
+// Read fetches the ref and returns the content of the file.
+func (s *gitSource) Read() ([]byte, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+	defer cancel()
+
+	dir, err := os.MkdirTemp("", "config-git-")
+	if err != nil {
+		return nil, "", err
+	}
+
+	defer os.RemoveAll(dir)
+
+	if _, err := s.git(ctx, dir, "init", "--quiet", "--bare"); err != nil {
+		return nil, "", err
+	}
+
+	// "--" keeps a URL or ref starting with "-" from being read as an option
+	if _, err := s.git(ctx, dir, "fetch", "--quiet", "--depth", "1", "--no-tags", "--", s.url, s.ref); err != nil {
+		return nil, "", err
+	}
+
+	data, err := s.git(ctx, dir, "show", "FETCH_HEAD:"+s.path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, s.opts.configType, nil
+}
+
+// Watch fetches the ref in the poll interval and sends a change if the file
+// changed.
+func (s *gitSource) Watch(ctx context.Context) <-chan struct{} {
+	return pollChanges(ctx, s.opts.pollInterval, func() ([]byte, error) {
+		data, _, err := s.Read()
+
+		return data, err
+	})
+}
+
+// git runs the git command in dir and returns its output.
+func (s *gitSource) git(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	// the credentials are passed in the environment to keep them out of the
+	// process list
+	switch {
+	case s.opts.token != "":
+		cmd.Env = append(cmd.Env, "GIT_CONFIG_COUNT=1", "GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Bearer "+s.opts.token)
+	case s.opts.username != "":
+		credentials := base64.StdEncoding.EncodeToString([]byte(s.opts.username + ":" + s.opts.password))
+		cmd.Env = append(cmd.Env, "GIT_CONFIG_COUNT=1", "GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic "+credentials)
+	}
+
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
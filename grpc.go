@@ -0,0 +1,192 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	grpcGetConfigMethod   = "/config.v1.ConfigService/GetConfig"
+	grpcWatchConfigMethod = "/config.v1.ConfigService/WatchConfig"
+)
+
+var errGRPCMessage = errors.New("invalid config service message")
+
+// WithGRPCSource is an option to load the config of the service from a central
+// config service implementing ConfigService of proto/config.proto. StartWatcher
+// streams WatchConfig and every config pushed by the server triggers a reload.
+// The format is sent by the server, WithFormat sets it if the server omits it.
+func WithGRPCSource[T any](conn grpc.ClientConnInterface, service string, opts ...RemoteOption) Option[T] {
+	return func(cl *loader[T]) {
+		cl.addSource(&grpcSource{
+			conn:    conn,
+			service: service,
+			opts:    newRemoteOptions("", opts),
+		})
+	}
+}
+
+// grpcSource reads and watches the config of a ConfigService.
+type grpcSource struct {
+	conn    grpc.ClientConnInterface
+	service string
+	opts    remoteOptions
+}
+
+// Read returns the current config of the service.
+func (s *grpcSource) Read() ([]byte, string, error) {
+	var response grpcConfig
+
+	ctx, cancel := s.opts.readContext()
+	defer cancel()
+
+	err := s.conn.Invoke(ctx, grpcGetConfigMethod, &grpcConfigRequest{service: s.service}, &response,
+		grpc.ForceCodec(grpcCodec{}))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get config: %w", err)
+	}
+
+	format := response.format
+	if format == "" {
+		format = s.opts.configType
+	}
+
+	return response.data, format, nil
+}
+
+// Watch sends a change for every config pushed by the server, a broken
+// stream is retried in the poll interval.
+func (s *grpcSource) Watch(ctx context.Context) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+
+	go func() {
+		defer close(changes)
+
+		for {
+			_ = s.watch(ctx, changes)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(s.opts.pollInterval):
+				notify(changes)
+			}
+		}
+	}()
+
+	return changes
+}
+
+// watch sends a change for every config of the stream, until it ends.
+func (s *grpcSource) watch(ctx context.Context, changes chan<- struct{}) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := s.conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, grpcWatchConfigMethod,
+		grpc.ForceCodec(grpcCodec{}))
+	if err != nil {
+		return err
+	}
+
+	if err := stream.SendMsg(&grpcConfigRequest{service: s.service}); err != nil {
+		return err
+	}
+
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+
+	for {
+		var response grpcConfig
+		if err := stream.RecvMsg(&response); err != nil {
+			return err
+		}
+
+		notify(changes)
+	}
+}
+
+// grpcConfigRequest is the GetConfigRequest message.
+type grpcConfigRequest struct {
+	service string
+}
+
+// grpcConfig is the Config message.
+type grpcConfig struct {
+	data   []byte
+	format string
+}
+
+// grpcCodec encodes the ConfigService messages in the protobuf wire format,
+// so no generated code is needed.
+type grpcCodec struct{}
+
+func (grpcCodec) Name() string {
+	return "proto"
+}
+
+func (grpcCodec) Marshal(v any) ([]byte, error) {
+	switch m := v.(type) {
+	case *grpcConfigRequest:
+		data := protowire.AppendTag(nil, 1, protowire.BytesType)
+
+		return protowire.AppendString(data, m.service), nil
+	case *grpcConfig:
+		data := protowire.AppendTag(nil, 1, protowire.BytesType)
+		data = protowire.AppendBytes(data, m.data)
+		data = protowire.AppendTag(data, 2, protowire.BytesType)
+
+		return protowire.AppendString(data, m.format), nil
+	}
+
+	return nil, fmt.Errorf("%w: %T", errGRPCMessage, v)
+}
+
+func (grpcCodec) Unmarshal(data []byte, v any) error {
+	m, ok := v.(*grpcConfig)
+	if !ok {
+		return fmt.Errorf("%w: %T", errGRPCMessage, v)
+	}
+
+	for len(data) > 0 {
+		number, wireType, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+
+		data = data[n:]
+
+		switch {
+		case number == 1 && wireType == protowire.BytesType:
+			value, n := protowire.ConsumeBytes(data)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+
+			m.data = append([]byte(nil), value...)
+			data = data[n:]
+		case number == 2 && wireType == protowire.BytesType:
+			value, n := protowire.ConsumeString(data)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+
+			m.format = value
+			data = data[n:]
+		default:
+			n := protowire.ConsumeFieldValue(number, wireType, data)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+
+			data = data[n:]
+		}
+	}
+
+	return nil
+}
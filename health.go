@@ -0,0 +1,72 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var errUnhealthy = errors.New("config unhealthy")
+
+// defaultHealthThreshold is the time a source may be unreachable before the
+// config is reported unhealthy.
+const defaultHealthThreshold = 5 * time.Minute
+
+// WithHealthThreshold is an option to set the time a source may be unreachable
+// before Healthy reports an error, 5 minutes by default.
+func WithHealthThreshold[T any](threshold time.Duration) Option[T] {
+	return func(cl *loader[T]) {
+		cl.healthThreshold = threshold
+	}
+}
+
+// Healthy returns an error if the config was not parsed, the default config of
+// WithDefault is used until a parse succeeds, the last reload failed or a
+// source has been unreachable for longer than the health threshold.
+func (c *loader[T]) Healthy() error {
+	if c.config.Load() == nil {
+		return fmt.Errorf("%w: config not loaded", errUnhealthy)
+	}
+
+	stats := c.stats.snapshot()
+
+	if stats.initialErr != nil {
+		return fmt.Errorf("%w: using the default config, parse failed: %w", errUnhealthy, stats.initialErr)
+	}
+
+	if stats.reloadErr != nil {
+		return fmt.Errorf("%w: last reload failed: %w", errUnhealthy, stats.reloadErr)
+	}
+
+	threshold := c.healthThreshold
+	if threshold == 0 {
+		threshold = defaultHealthThreshold
+	}
+
+	for name, since := range stats.unreachable {
+		if time.Since(since) > threshold {
+			return fmt.Errorf("%w: source %s unreachable since %s", errUnhealthy, name, since.Format(time.RFC3339))
+		}
+	}
+
+	return nil
+}
+
+// HealthHandler returns an http.Handler for readiness probes, it responds with
+// 200 OK if the config is healthy and 503 Service Unavailable with the error
+// otherwise.
+func (c *loader[T]) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+		if err := c.Healthy(); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintln(w, err)
+
+			return
+		}
+
+		fmt.Fprintln(w, "ok")
+	})
+}
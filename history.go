@@ -0,0 +1,196 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+var errNoSnapshot = errors.New("no config snapshot")
+
+// Snapshot is a parsed config of the history.
+type Snapshot[T any] struct {
+	// Version counts the parsed configs of the loader, starting at 1.
+	Version int
+	// Time is the time the config was parsed or restored.
+	Time time.Time
+	// Checksum is the SHA-256 checksum of the effective config like in the
+	// audit log.
+	Checksum string
+	Config   T
+
+	state *configState // settings of the config, restored by Rollback
+}
+
+// WithHistory is an option to keep the last n parsed configs, see History and
+// Rollback.
+func WithHistory[T any](n int) Option[T] {
+	return func(cl *loader[T]) {
+		cl.historySize = n
+	}
+}
+
+// History returns the snapshots of the last parsed configs of WithHistory, the
+// current config first.
+func (c *loader[T]) History() []Snapshot[T] {
+	c.historyMu.Lock()
+	defer c.historyMu.Unlock()
+
+	history := slices.Clone(c.history)
+	slices.Reverse(history)
+
+	return history
+}
+
+// Rollback restores the config n versions before the current config of the
+// history, e.g. 1 for the previous config. The settings of the snapshot are
+// restored and parsed again like on a reload, so Dump, Export, Provenance and
+// LastDiff agree with Load, and the change callbacks are called. The restored
+// config is used until the next reload and is added to the history as the
+// current config.
+func (c *loader[T]) Rollback(n int) error {
+	ctx, span := c.startSpan(context.Background(), "config.Rollback")
+
+	c.reloadMu.Lock()
+	c.historyMu.Lock()
+
+	i := len(c.history) - 1 - n
+	if n < 1 || i < 0 {
+		err := fmt.Errorf("%w: %d versions back, the history has %d versions", errNoSnapshot, n, len(c.history))
+
+		c.historyMu.Unlock()
+		c.reloadMu.Unlock()
+		endSpan(span, err)
+
+		return err
+	}
+
+	snapshot := c.history[i]
+	c.historyMu.Unlock()
+
+	previous := c.config.Load()
+	previousSettings, _ := c.settings()
+	previousState := c.captureState()
+
+	err := c.restoreState(snapshot.state)
+	if err == nil {
+		err = c.parseContext(ctx)
+	}
+
+	if err != nil {
+		if restoreErr := c.restoreState(previousState); restoreErr != nil {
+			c.logger.Error("Failed to restore config", "error", restoreErr)
+		}
+
+		c.audit(triggerRollback, err, nil, nil)
+		c.reloadMu.Unlock()
+		endSpan(span, err)
+
+		return err
+	}
+
+	diff := c.publish(span, triggerRollback, previousSettings)
+	current := c.config.Load()
+	c.reloadMu.Unlock()
+
+	c.logger.Info("Config rolled back", "version", snapshot.Version, "changes", len(diff))
+	endSpan(span, nil)
+	c.notify(previous, current, diff, nil)
+
+	return nil
+}
+
+// configState is the state of the config layer of viper, the recorded sources
+// and the values of Set, which is restored by Rollback.
+type configState struct {
+	settings  map[string]any
+	origins   map[string]SourceInfo
+	layers    map[Layer]map[string]layerValue
+	overrides map[string]any
+}
+
+// captureState returns the current state of the config layer.
+func (c *loader[T]) captureState() *configState {
+	layers := make(map[Layer]map[string]layerValue, len(c.layers))
+	for layer, values := range c.layers {
+		layers[layer] = maps.Clone(values)
+	}
+
+	return &configState{
+		settings:  cloneSettings(c.allSettings()),
+		origins:   maps.Clone(c.origins),
+		layers:    layers,
+		overrides: maps.Clone(c.overrides),
+	}
+}
+
+// restoreState replaces the config layer, the recorded sources and the values
+// of Set with the state.
+func (c *loader[T]) restoreState(state *configState) error {
+	// ReadConfig replaces the config before decoding, the result of the empty
+	// input is irrelevant
+	_ = c.viper.ReadConfig(strings.NewReader(""))
+
+	c.origins = maps.Clone(state.origins)
+	c.layers = make(map[Layer]map[string]layerValue, len(state.layers))
+
+	for layer, values := range state.layers {
+		c.layers[layer] = maps.Clone(values)
+	}
+
+	for key := range c.overrides {
+		if _, ok := state.overrides[key]; !ok {
+			c.setOverride(key, nil)
+		}
+	}
+
+	for key, value := range state.overrides {
+		c.setOverride(key, value)
+	}
+
+	// viper merges into the nested maps, the snapshot must not change
+	return c.viper.MergeConfigMap(cloneSettings(state.settings))
+}
+
+// cloneSettings returns a copy of the nested settings.
+func cloneSettings(settings map[string]any) map[string]any {
+	clone := make(map[string]any, len(settings))
+
+	for key, value := range settings {
+		if nested, ok := value.(map[string]any); ok {
+			value = cloneSettings(nested)
+		}
+
+		clone[key] = value
+	}
+
+	return clone
+}
+
+// addSnapshot adds the config to the history, the oldest snapshot is dropped
+// if the history is full.
+func (c *loader[T]) addSnapshot(config T, checksum string) {
+	if c.historySize <= 0 {
+		return
+	}
+
+	c.historyMu.Lock()
+	defer c.historyMu.Unlock()
+
+	c.version++
+	c.history = append(c.history, Snapshot[T]{
+		Version:  c.version,
+		Time:     time.Now(),
+		Checksum: checksum,
+		Config:   config,
+		state:    c.captureState(),
+	})
+
+	if len(c.history) > c.historySize {
+		c.history = slices.Delete(c.history, 0, len(c.history)-c.historySize)
+	}
+}
@@ -0,0 +1,20 @@
+package config
+
+// WithPreParseHook is an option to add a hook, which runs on the raw settings
+// before they are decoded into the config struct, e.g. to normalize values or
+// rename keys. The keys are lower case, nested sections are nested maps. The
+// hooks run in order, an error fails the parse.
+func WithPreParseHook[T any](hook func(raw map[string]any) error) Option[T] {
+	return func(cl *loader[T]) {
+		cl.preParseHooks = append(cl.preParseHooks, hook)
+	}
+}
+
+// WithPostParseHook is an option to add a hook, which runs on the decoded
+// config before it is stored, e.g. to compute derived fields. The hooks run in
+// order, an error fails the parse and the current config is kept.
+func WithPostParseHook[T any](hook func(config *T) error) Option[T] {
+	return func(cl *loader[T]) {
+		cl.postParseHooks = append(cl.postParseHooks, hook)
+	}
+}
@@ -0,0 +1,106 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Client calls AWS APIs with signed requests.
+type Client struct {
+	HTTPClient  *http.Client
+	Credentials *CredentialsProvider
+	Region      string
+	// Endpoint overrides the service endpoint, e.g. for LocalStack.
+	Endpoint string
+}
+
+// NewClient returns a client for the region, an empty region is taken from the environment.
+func NewClient(httpClient *http.Client, region, endpoint string) (*Client, error) {
+	if region == "" {
+		var err error
+		if region, err = Region(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Client{
+		HTTPClient:  httpClient,
+		Credentials: NewCredentialsProvider(httpClient),
+		Region:      region,
+		Endpoint:    endpoint,
+	}, nil
+}
+
+// ServiceEndpoint returns the endpoint of the service in the client region.
+func (c *Client) ServiceEndpoint(service string) string {
+	if c.Endpoint != "" {
+		return c.Endpoint
+	}
+
+	return fmt.Sprintf("https://%s.%s.amazonaws.com", service, c.Region)
+}
+
+// CallJSON calls an operation of an AWS JSON 1.1 protocol API like SSM or
+// Secrets Manager, target is e.g. "AmazonSSM.GetParametersByPath".
+func (c *Client) CallJSON(ctx context.Context, service, target string, input, output any) error {
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.ServiceEndpoint(service)+"/", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", target)
+
+	body, err := c.Do(ctx, req, payload, service)
+	if err != nil {
+		return fmt.Errorf("%s failed: %w", target, err)
+	}
+
+	return json.Unmarshal(body, output)
+}
+
+// Do signs and sends the request and returns the body of a successful response.
+func (c *Client) Do(ctx context.Context, req *http.Request, payload []byte, service string) ([]byte, error) {
+	if err := c.Sign(ctx, req, payload, service); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s: %s", errStatus, resp.Status, bytes.TrimSpace(body))
+	}
+
+	return body, nil
+}
+
+// Sign signs the request with the client credentials and region.
+func (c *Client) Sign(ctx context.Context, req *http.Request, payload []byte, service string) error {
+	creds, err := c.Credentials.Retrieve(ctx)
+	if err != nil {
+		return err
+	}
+
+	Sign(req, payload, creds, service, c.Region, time.Now())
+
+	return nil
+}
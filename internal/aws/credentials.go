@@ -0,0 +1,340 @@
+package aws
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	containerCredentialsHost = "http://169.254.170.2"
+	imdsEndpoint             = "http://169.254.169.254"
+	imdsTimeout              = time.Second
+	expiryWindow             = 5 * time.Minute
+)
+
+var (
+	// ErrNoCredentials is returned if no provider of the chain has credentials.
+	ErrNoCredentials = errors.New("no AWS credentials found")
+	errNoRegion      = errors.New("no AWS region set, use AWS_REGION")
+	errStatus        = errors.New("unexpected status")
+)
+
+// Credentials are AWS credentials, Expires is zero for static credentials.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Expires         time.Time
+}
+
+// CredentialsProvider retrieves credentials with the default chain:
+// environment variables, shared credentials file, web identity token (EKS),
+// container credentials (ECS) and the EC2 instance metadata service.
+// Credentials are cached until shortly before they expire.
+type CredentialsProvider struct {
+	client *http.Client
+	mu     sync.Mutex
+	creds  Credentials
+}
+
+// NewCredentialsProvider returns a provider using client for the HTTP based
+// credential sources.
+func NewCredentialsProvider(client *http.Client) *CredentialsProvider {
+	return &CredentialsProvider{client: client}
+}
+
+// Retrieve returns cached credentials or retrieves them from the chain.
+func (p *CredentialsProvider) Retrieve(ctx context.Context) (Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.creds.AccessKeyID != "" && (p.creds.Expires.IsZero() || time.Until(p.creds.Expires) > expiryWindow) {
+		return p.creds, nil
+	}
+
+	providers := []func(context.Context) (Credentials, bool, error){
+		p.fromEnv,
+		p.fromSharedCredentialsFile,
+		p.fromWebIdentity,
+		p.fromContainer,
+		p.fromIMDS,
+	}
+
+	for _, provider := range providers {
+		creds, ok, err := provider(ctx)
+		if err != nil {
+			return Credentials{}, err
+		}
+
+		if ok {
+			p.creds = creds
+
+			return creds, nil
+		}
+	}
+
+	return Credentials{}, ErrNoCredentials
+}
+
+// Region returns the region of the AWS_REGION or AWS_DEFAULT_REGION environment variable.
+func Region() (string, error) {
+	for _, env := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if region := os.Getenv(env); region != "" {
+			return region, nil
+		}
+	}
+
+	return "", errNoRegion
+}
+
+func (p *CredentialsProvider) fromEnv(context.Context) (Credentials, bool, error) {
+	creds := Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+
+	return creds, creds.AccessKeyID != "" && creds.SecretAccessKey != "", nil
+}
+
+func (p *CredentialsProvider) fromSharedCredentialsFile(context.Context) (Credentials, bool, error) {
+	filename := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if filename == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return Credentials{}, false, nil
+		}
+
+		filename = filepath.Join(home, ".aws", "credentials")
+	}
+
+	profile := os.Getenv("AWS_PROFILE")
+	if profile == "" {
+		profile = "default"
+	}
+
+	file, err := os.Open(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return Credentials{}, false, nil
+	} else if err != nil {
+		return Credentials{}, false, err
+	}
+	defer file.Close()
+
+	var (
+		creds   Credentials
+		section string
+	)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			section = strings.TrimSpace(line[1 : len(line)-1])
+		case section == profile:
+			key, value, _ := strings.Cut(line, "=")
+
+			switch strings.TrimSpace(key) {
+			case "aws_access_key_id":
+				creds.AccessKeyID = strings.TrimSpace(value)
+			case "aws_secret_access_key":
+				creds.SecretAccessKey = strings.TrimSpace(value)
+			case "aws_session_token":
+				creds.SessionToken = strings.TrimSpace(value)
+			}
+		}
+	}
+
+	return creds, creds.AccessKeyID != "" && creds.SecretAccessKey != "", scanner.Err()
+}
+
+func (p *CredentialsProvider) fromWebIdentity(ctx context.Context) (Credentials, bool, error) {
+	tokenFile, roleARN := os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"), os.Getenv("AWS_ROLE_ARN")
+	if tokenFile == "" || roleARN == "" {
+		return Credentials{}, false, nil
+	}
+
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return Credentials{}, false, err
+	}
+
+	region, err := Region()
+	if err != nil {
+		return Credentials{}, false, err
+	}
+
+	sessionName := os.Getenv("AWS_ROLE_SESSION_NAME")
+	if sessionName == "" {
+		sessionName = fmt.Sprintf("config-%d", time.Now().Unix())
+	}
+
+	query := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {roleARN},
+		"RoleSessionName":  {sessionName},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+
+	body, err := p.get(ctx, fmt.Sprintf("https://sts.%s.amazonaws.com/?%s", region, query.Encode()), nil)
+	if err != nil {
+		return Credentials{}, false, fmt.Errorf("failed to assume role with web identity: %w", err)
+	}
+
+	var response struct {
+		Credentials struct {
+			AccessKeyID     string    `xml:"AccessKeyId"`
+			SecretAccessKey string    `xml:"SecretAccessKey"`
+			SessionToken    string    `xml:"SessionToken"`
+			Expiration      time.Time `xml:"Expiration"`
+		} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	}
+
+	if err := xml.Unmarshal(body, &response); err != nil {
+		return Credentials{}, false, err
+	}
+
+	return Credentials{
+		AccessKeyID:     response.Credentials.AccessKeyID,
+		SecretAccessKey: response.Credentials.SecretAccessKey,
+		SessionToken:    response.Credentials.SessionToken,
+		Expires:         response.Credentials.Expiration,
+	}, true, nil
+}
+
+func (p *CredentialsProvider) fromContainer(ctx context.Context) (Credentials, bool, error) {
+	endpoint := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI")
+	if relativeURI := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); relativeURI != "" {
+		endpoint = containerCredentialsHost + relativeURI
+	}
+
+	if endpoint == "" {
+		return Credentials{}, false, nil
+	}
+
+	header := http.Header{}
+
+	token := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN")
+	if tokenFile := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"); tokenFile != "" {
+		data, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return Credentials{}, false, err
+		}
+
+		token = strings.TrimSpace(string(data))
+	}
+
+	if token != "" {
+		header.Set("Authorization", token)
+	}
+
+	body, err := p.get(ctx, endpoint, header)
+	if err != nil {
+		return Credentials{}, false, fmt.Errorf("failed to get container credentials: %w", err)
+	}
+
+	return decodeJSONCredentials(body)
+}
+
+func (p *CredentialsProvider) fromIMDS(ctx context.Context) (Credentials, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, imdsTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, imdsEndpoint+"/latest/api/token", nil)
+	if err != nil {
+		return Credentials{}, false, err
+	}
+
+	req.Header.Set("X-Aws-Ec2-Metadata-Token-Ttl-Seconds", "21600")
+
+	token, err := p.do(req)
+	if err != nil {
+		// Not running on EC2
+		return Credentials{}, false, nil
+	}
+
+	header := http.Header{"X-Aws-Ec2-Metadata-Token": {string(token)}}
+
+	role, err := p.get(ctx, imdsEndpoint+"/latest/meta-data/iam/security-credentials/", header)
+	if err != nil {
+		return Credentials{}, false, nil
+	}
+
+	roleName, _, _ := strings.Cut(strings.TrimSpace(string(role)), "\n")
+
+	body, err := p.get(ctx, imdsEndpoint+"/latest/meta-data/iam/security-credentials/"+roleName, header)
+	if err != nil {
+		return Credentials{}, false, fmt.Errorf("failed to get instance credentials: %w", err)
+	}
+
+	return decodeJSONCredentials(body)
+}
+
+// decodeJSONCredentials decodes the credentials format of the container and
+// instance metadata endpoints.
+func decodeJSONCredentials(body []byte) (Credentials, bool, error) {
+	var response struct {
+		AccessKeyID     string    `json:"AccessKeyId"`
+		SecretAccessKey string    `json:"SecretAccessKey"`
+		Token           string    `json:"Token"`
+		Expiration      time.Time `json:"Expiration"`
+	}
+
+	if err := json.Unmarshal(body, &response); err != nil {
+		return Credentials{}, false, err
+	}
+
+	return Credentials{
+		AccessKeyID:     response.AccessKeyID,
+		SecretAccessKey: response.SecretAccessKey,
+		SessionToken:    response.Token,
+		Expires:         response.Expiration,
+	}, true, nil
+}
+
+func (p *CredentialsProvider) get(ctx context.Context, url string, header http.Header) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	return p.do(req)
+}
+
+func (p *CredentialsProvider) do(req *http.Request) ([]byte, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s %s", errStatus, req.URL.Redacted(), resp.Status)
+	}
+
+	return body, nil
+}
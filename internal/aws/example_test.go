@@ -0,0 +1,23 @@
+package aws_test
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"schneider.vip/config/internal/aws"
+)
+
+// ExampleSign signs the "get-vanilla" request of the AWS Signature Version 4 test suite.
+func ExampleSign() {
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	creds := aws.Credentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+
+	aws.Sign(req, nil, creds, "service", "us-east-1", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+	fmt.Println(req.Header.Get("Authorization"))
+
+	// Output: AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31
+}
@@ -0,0 +1,138 @@
+// Package aws implements the parts of the AWS APIs used by the config sources:
+// Signature Version 4 request signing and the default credential chain.
+package aws
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	signAlgorithm = "AWS4-HMAC-SHA256"
+	amzDateFormat = "20060102T150405Z"
+)
+
+// Sign signs the request with Signature Version 4. The payload must be the
+// request body, all headers set before calling Sign are signed.
+func Sign(req *http.Request, payload []byte, creds Credentials, service, region string, now time.Time) {
+	amzDate := now.UTC().Format(amzDateFormat)
+	date := amzDate[:8]
+	payloadHash := hashHex(payload)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	if service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for key, values := range req.Header {
+		headers[strings.ToLower(key)] = strings.Join(values, ",")
+	}
+
+	signedHeaders := make([]string, 0, len(headers))
+	for key := range headers {
+		signedHeaders = append(signedHeaders, key)
+	}
+
+	slices.Sort(signedHeaders)
+
+	var canonicalHeaders strings.Builder
+	for _, key := range signedHeaders {
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", key, strings.TrimSpace(headers[key]))
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath(req.URL, service),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{signAlgorithm, amzDate, scope, hashHex([]byte(canonicalRequest))}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signAlgorithm, creds.AccessKeyID, scope, strings.Join(signedHeaders, ";"),
+		hex.EncodeToString(hmacSHA256(signingKey, stringToSign))))
+}
+
+// canonicalPath returns the URI encoded path, S3 paths are encoded only once.
+func canonicalPath(u *url.URL, service string) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+
+	if service == "s3" {
+		return path
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery returns the query sorted by key and value.
+func canonicalQuery(query url.Values) string {
+	params := make([]string, 0, len(query))
+
+	for key, values := range query {
+		for _, value := range values {
+			params = append(params, uriEncode(key)+"="+uriEncode(value))
+		}
+	}
+
+	slices.Sort(params)
+
+	return strings.Join(params, "&")
+}
+
+// uriEncode encodes all characters except the unreserved characters of RFC 3986.
+func uriEncode(s string) string {
+	var encoded strings.Builder
+
+	for _, b := range []byte(s) {
+		if 'A' <= b && b <= 'Z' || 'a' <= b && b <= 'z' || '0' <= b && b <= '9' || strings.IndexByte("-_.~", b) >= 0 {
+			encoded.WriteByte(b)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+
+	return encoded.String()
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,216 @@
+// Package azure implements the Microsoft Entra ID token acquisition and the
+// App Configuration HMAC authentication used by the config sources.
+package azure
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	imdsTokenURL = "http://169.254.169.254/metadata/identity/oauth2/token"
+	expiryWindow = 5 * time.Minute
+)
+
+var (
+	// ErrNoCredentials is returned if no credentials are found.
+	ErrNoCredentials = errors.New("no Azure credentials found")
+	errStatus        = errors.New("unexpected status")
+)
+
+// TokenProvider returns access tokens of the default credentials: a client
+// secret or workload identity of the AZURE_* environment variables, the App
+// Service managed identity or the managed identity of the instance metadata
+// service. Tokens are cached per scope until shortly before they expire.
+type TokenProvider struct {
+	client *http.Client
+	mu     sync.Mutex
+	tokens map[string]token
+}
+
+type token struct {
+	accessToken string
+	expires     time.Time
+}
+
+// NewTokenProvider returns a token provider using client for token requests.
+func NewTokenProvider(client *http.Client) *TokenProvider {
+	return &TokenProvider{client: client, tokens: make(map[string]token)}
+}
+
+// Token returns a cached or new access token for the scope, e.g.
+// "https://vault.azure.net/.default".
+func (p *TokenProvider) Token(ctx context.Context, scope string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if t, ok := p.tokens[scope]; ok && time.Until(t.expires) > expiryWindow {
+		return t.accessToken, nil
+	}
+
+	req, err := p.tokenRequest(ctx, scope)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := p.do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get Azure token: %w", err)
+	}
+
+	var response struct {
+		AccessToken string          `json:"access_token"`
+		ExpiresIn   json.RawMessage `json:"expires_in"`
+	}
+
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("failed to decode Azure token: %w", err)
+	}
+
+	// expires_in is a number or a string, depending on the endpoint
+	var expiresIn int
+	if err := json.Unmarshal(response.ExpiresIn, &expiresIn); err != nil {
+		var s string
+		_ = json.Unmarshal(response.ExpiresIn, &s)
+		expiresIn, _ = strconv.Atoi(s)
+	}
+
+	p.tokens[scope] = token{
+		accessToken: response.AccessToken,
+		expires:     time.Now().Add(time.Duration(expiresIn) * time.Second),
+	}
+
+	return response.AccessToken, nil
+}
+
+// tokenRequest returns the token request of the first available credential.
+func (p *TokenProvider) tokenRequest(ctx context.Context, scope string) (*http.Request, error) {
+	tenantID, clientID := os.Getenv("AZURE_TENANT_ID"), os.Getenv("AZURE_CLIENT_ID")
+	resource := strings.TrimSuffix(scope, "/.default")
+
+	switch {
+	case tenantID != "" && clientID != "" && os.Getenv("AZURE_CLIENT_SECRET") != "":
+		return clientCredentialsRequest(ctx, tenantID, url.Values{
+			"client_id":     {clientID},
+			"client_secret": {os.Getenv("AZURE_CLIENT_SECRET")},
+			"scope":         {scope},
+		})
+	case tenantID != "" && clientID != "" && os.Getenv("AZURE_FEDERATED_TOKEN_FILE") != "":
+		assertion, err := os.ReadFile(os.Getenv("AZURE_FEDERATED_TOKEN_FILE"))
+		if err != nil {
+			return nil, err
+		}
+
+		return clientCredentialsRequest(ctx, tenantID, url.Values{
+			"client_id":             {clientID},
+			"client_assertion":      {strings.TrimSpace(string(assertion))},
+			"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+			"scope":                 {scope},
+		})
+	case os.Getenv("IDENTITY_ENDPOINT") != "" && os.Getenv("IDENTITY_HEADER") != "":
+		query := url.Values{"api-version": {"2019-08-01"}, "resource": {resource}}
+		if clientID != "" {
+			query.Set("client_id", clientID)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, os.Getenv("IDENTITY_ENDPOINT")+"?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("X-Identity-Header", os.Getenv("IDENTITY_HEADER"))
+
+		return req, nil
+	default:
+		query := url.Values{"api-version": {"2018-02-01"}, "resource": {resource}}
+		if clientID != "" {
+			query.Set("client_id", clientID)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, imdsTokenURL+"?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Metadata", "true")
+
+		return req, nil
+	}
+}
+
+// clientCredentialsRequest returns a client credentials token request.
+func clientCredentialsRequest(ctx context.Context, tenantID string, form url.Values) (*http.Request, error) {
+	form.Set("grant_type", "client_credentials")
+
+	authority := os.Getenv("AZURE_AUTHORITY_HOST")
+	if authority == "" {
+		authority = "https://login.microsoftonline.com"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		strings.TrimSuffix(authority, "/")+"/"+tenantID+"/oauth2/v2.0/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return req, nil
+}
+
+func (p *TokenProvider) do(req *http.Request) ([]byte, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrNoCredentials, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s %s", errStatus, req.URL.Redacted(), resp.Status)
+	}
+
+	return body, nil
+}
+
+// SignHMAC signs an App Configuration request with the credential and secret
+// of an access key. The request must not have a body.
+func SignHMAC(req *http.Request, credential, secret string, now time.Time) error {
+	key, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return fmt.Errorf("invalid access key secret: %w", err)
+	}
+
+	date := now.UTC().Format(http.TimeFormat)
+	contentHash := sha256.Sum256(nil)
+	encodedHash := base64.StdEncoding.EncodeToString(contentHash[:])
+
+	stringToSign := strings.Join([]string{req.Method, req.URL.RequestURI(), date + ";" + req.URL.Host + ";" + encodedHash}, "\n")
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(stringToSign))
+
+	req.Header.Set("x-ms-date", date)
+	req.Header.Set("x-ms-content-sha256", encodedHash)
+	req.Header.Set("Authorization", fmt.Sprintf("HMAC-SHA256 Credential=%s&SignedHeaders=x-ms-date;host;x-ms-content-sha256&Signature=%s",
+		credential, base64.StdEncoding.EncodeToString(mac.Sum(nil))))
+
+	return nil
+}
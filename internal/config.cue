@@ -0,0 +1,8 @@
+HTTPListener: "0.0.0.0:8080"
+
+#port: 5432
+
+databaseConfig: {
+	host: "cue.example.com"
+	port: #port
+}
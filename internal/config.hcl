@@ -0,0 +1,14 @@
+HTTPListener = "0.0.0.0:8080"
+
+databaseConfig {
+  host = "hcl.example.com"
+  port = 5432
+}
+
+upstream "auth" {
+  url = "http://auth:8080"
+}
+
+upstream "billing" {
+  url = "http://billing:8080"
+}
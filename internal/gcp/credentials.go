@@ -0,0 +1,299 @@
+// Package gcp implements the Google Cloud Application Default Credentials
+// used by the config sources.
+package gcp
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+	defaultTokenURL    = "https://oauth2.googleapis.com/token"
+	metadataURL        = "http://metadata.google.internal/computeMetadata/v1"
+	expiryWindow       = time.Minute
+)
+
+var (
+	// ErrNoCredentials is returned if no Application Default Credentials are found.
+	ErrNoCredentials = errors.New("no Google Cloud credentials found")
+	errNoProject     = errors.New("no Google Cloud project found, use GOOGLE_CLOUD_PROJECT")
+	errInvalidKey    = errors.New("invalid private key")
+	errStatus        = errors.New("unexpected status")
+)
+
+// credentialsFile is the content of a service account key or gcloud user
+// credentials file.
+type credentialsFile struct {
+	Type         string `json:"type"`
+	ProjectID    string `json:"project_id"`
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	TokenURI     string `json:"token_uri"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenProvider returns access tokens of the Application Default Credentials:
+// the GOOGLE_APPLICATION_CREDENTIALS file, the gcloud user credentials and the
+// metadata server. Tokens are cached until shortly before they expire.
+type TokenProvider struct {
+	client  *http.Client
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewTokenProvider returns a token provider using client for token requests.
+func NewTokenProvider(client *http.Client) *TokenProvider {
+	return &TokenProvider{client: client}
+}
+
+// Token returns a cached or new access token.
+func (p *TokenProvider) Token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != "" && time.Until(p.expires) > expiryWindow {
+		return p.token, nil
+	}
+
+	creds, err := readCredentialsFile()
+	if err != nil {
+		return "", err
+	}
+
+	var form url.Values
+
+	switch {
+	case creds == nil:
+		return p.metadataToken(ctx)
+	case creds.Type == "service_account":
+		assertion, err := signJWT(creds)
+		if err != nil {
+			return "", err
+		}
+
+		form = url.Values{
+			"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+			"assertion":  {assertion},
+		}
+	case creds.Type == "authorized_user":
+		form = url.Values{
+			"grant_type":    {"refresh_token"},
+			"client_id":     {creds.ClientID},
+			"client_secret": {creds.ClientSecret},
+			"refresh_token": {creds.RefreshToken},
+		}
+	default:
+		return "", fmt.Errorf("%w: unsupported credentials type %q", ErrNoCredentials, creds.Type)
+	}
+
+	tokenURL := creds.TokenURI
+	if tokenURL == "" {
+		tokenURL = defaultTokenURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return p.requestToken(req)
+}
+
+// ProjectID returns the project of GOOGLE_CLOUD_PROJECT, the service account
+// key or the metadata server.
+func (p *TokenProvider) ProjectID(ctx context.Context) (string, error) {
+	if project := os.Getenv("GOOGLE_CLOUD_PROJECT"); project != "" {
+		return project, nil
+	}
+
+	if creds, err := readCredentialsFile(); err == nil && creds != nil && creds.ProjectID != "" {
+		return creds.ProjectID, nil
+	}
+
+	body, err := p.metadata(ctx, "/project/project-id")
+	if err != nil {
+		return "", errNoProject
+	}
+
+	return string(body), nil
+}
+
+// readCredentialsFile reads the GOOGLE_APPLICATION_CREDENTIALS file or the
+// gcloud well-known file, returns nil if none exists.
+func readCredentialsFile() (*credentialsFile, error) {
+	filename := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	if filename == "" {
+		filename = wellKnownFile()
+		if _, err := os.Stat(filename); err != nil {
+			return nil, nil // no credentials file, use the metadata server
+		}
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var creds credentialsFile
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", filename, err)
+	}
+
+	return &creds, nil
+}
+
+// wellKnownFile returns the path of the gcloud application default credentials.
+func wellKnownFile() string {
+	if dir := os.Getenv("CLOUDSDK_CONFIG"); dir != "" {
+		return filepath.Join(dir, "application_default_credentials.json")
+	}
+
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("APPDATA"), "gcloud", "application_default_credentials.json")
+	}
+
+	home, _ := os.UserHomeDir()
+
+	return filepath.Join(home, ".config", "gcloud", "application_default_credentials.json")
+}
+
+// signJWT returns a signed JWT assertion of the service account.
+func signJWT(creds *credentialsFile) (string, error) {
+	block, _ := pem.Decode([]byte(creds.PrivateKey))
+	if block == nil {
+		return "", errInvalidKey
+	}
+
+	parsedKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		if parsedKey, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+			return "", fmt.Errorf("%w: %w", errInvalidKey, err)
+		}
+	}
+
+	key, ok := parsedKey.(*rsa.PrivateKey)
+	if !ok {
+		return "", errInvalidKey
+	}
+
+	tokenURL := creds.TokenURI
+	if tokenURL == "" {
+		tokenURL = defaultTokenURL
+	}
+
+	now := time.Now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]any{
+		"iss":   creds.ClientEmail,
+		"scope": cloudPlatformScope,
+		"aud":   tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(unsigned))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// metadataToken requests a token of the default service account from the
+// metadata server.
+func (p *TokenProvider) metadataToken(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		metadataURL+"/instance/service-accounts/default/token?scopes="+cloudPlatformScope, nil)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	token, err := p.requestToken(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrNoCredentials, err)
+	}
+
+	return token, nil
+}
+
+// requestToken sends the token request and caches the returned token.
+func (p *TokenProvider) requestToken(req *http.Request) (string, error) {
+	body, err := p.do(req)
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("failed to decode token response: %w", err)
+	}
+
+	p.token = response.AccessToken
+	p.expires = time.Now().Add(time.Duration(response.ExpiresIn) * time.Second)
+
+	return p.token, nil
+}
+
+// metadata returns a value of the metadata server.
+func (p *TokenProvider) metadata(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	return p.do(req)
+}
+
+func (p *TokenProvider) do(req *http.Request) ([]byte, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s %s", errStatus, req.URL.Redacted(), resp.Status)
+	}
+
+	return body, nil
+}
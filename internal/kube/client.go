@@ -0,0 +1,133 @@
+// Package kube implements a minimal client of the Kubernetes API server used
+// by the config sources.
+package kube
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// serviceAccountDir is the directory of the mounted service account.
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+var (
+	// ErrNotInCluster is returned if no endpoint is given and the process does
+	// not run in a Kubernetes pod.
+	ErrNotInCluster = errors.New("not running in a Kubernetes cluster, set an endpoint")
+	errInvalidCA    = errors.New("invalid service account CA certificate")
+)
+
+// Client sends authenticated requests to the Kubernetes API server.
+type Client struct {
+	endpoint   string
+	httpClient *http.Client
+	token      string
+	tokenFile  string // re-read on every request, as projected tokens rotate
+}
+
+// NewClient returns a client of the API server at endpoint, authenticated with
+// token. Without endpoint, the in-cluster config of the pod service account is
+// used and, if httpClient is nil, an HTTP client trusting the cluster CA.
+func NewClient(httpClient *http.Client, endpoint, token string) (*Client, error) {
+	c := &Client{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		httpClient: httpClient,
+		token:      token,
+	}
+
+	if c.endpoint == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return nil, ErrNotInCluster
+		}
+
+		c.endpoint = "https://" + net.JoinHostPort(host, port)
+
+		if c.token == "" {
+			c.tokenFile = filepath.Join(serviceAccountDir, "token")
+		}
+
+		if c.httpClient == nil {
+			client, err := inClusterHTTPClient()
+			if err != nil {
+				return nil, err
+			}
+
+			c.httpClient = client
+		}
+	}
+
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+
+	return c, nil
+}
+
+// inClusterHTTPClient returns an HTTP client trusting the cluster CA.
+func inClusterHTTPClient() (*http.Client, error) {
+	ca, err := os.ReadFile(filepath.Join(serviceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errInvalidCA
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// HTTPClient returns the HTTP client to send the requests with.
+func (c *Client) HTTPClient() *http.Client {
+	return c.httpClient
+}
+
+// NewRequest returns an authenticated GET request of the API path, e.g.
+// "/api/v1/namespaces/default/configmaps/app".
+func (c *Client) NewRequest(ctx context.Context, path string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	token := c.token
+	if c.tokenFile != "" {
+		data, err := os.ReadFile(c.tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read service account token: %w", err)
+		}
+
+		token = strings.TrimSpace(string(data))
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return req, nil
+}
+
+// Namespace returns the namespace of the pod service account, or "default".
+func Namespace() string {
+	data, err := os.ReadFile(filepath.Join(serviceAccountDir, "namespace"))
+	if err != nil {
+		return "default"
+	}
+
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,6 @@
+HTTPListener: =~"^[^:]*:[0-9]+$"
+
+databaseConfig: {
+	host: string & !=""
+	port: int & >0 & <65536
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var errUnterminatedVariable = errors.New("unterminated variable")
+
+// WithEnvInterpolation is an option to expand environment variables in string
+// values before unmarshalling, like Docker Compose:
+//
+//	${VAR}          value of VAR, empty if unset
+//	${VAR:-default} default if VAR is unset or empty
+//	${VAR-default}  default if VAR is unset
+//	${VAR:?message} error if VAR is unset or empty
+//	${VAR?message}  error if VAR is unset
+//	$$              a literal $
+func WithEnvInterpolation[T any]() Option[T] {
+	return func(cl *loader[T]) {
+		cl.envInterpolation = true
+	}
+}
+
+// interpolationHook expands the environment variables of string values, the
+// variables are looked up by lookupEnv.
+func interpolationHook(lookupEnv func(string) (string, bool)) func(reflect.Type, reflect.Type, any) (any, error) {
+	return func(_ reflect.Type, _ reflect.Type, data any) (any, error) {
+		value, ok := data.(string)
+		if !ok || !strings.Contains(value, "$") {
+			return data, nil
+		}
+
+		return expandEnv(value, lookupEnv)
+	}
+}
+
+// expandEnv expands the ${...} placeholders of s.
+func expandEnv(s string, lookupEnv func(string) (string, bool)) (string, error) {
+	var expanded strings.Builder
+
+	for {
+		i := strings.IndexByte(s, '$')
+		if i < 0 || i == len(s)-1 {
+			expanded.WriteString(s)
+
+			return expanded.String(), nil
+		}
+
+		expanded.WriteString(s[:i])
+
+		switch s[i+1] {
+		case '$':
+			expanded.WriteByte('$')
+			s = s[i+2:]
+		case '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("%w in %q", errUnterminatedVariable, s)
+			}
+
+			value, err := lookupVariable(s[i+2:i+end], lookupEnv)
+			if err != nil {
+				return "", err
+			}
+
+			expanded.WriteString(value)
+			s = s[i+end+1:]
+		default:
+			expanded.WriteByte('$')
+			s = s[i+1:]
+		}
+	}
+}
+
+// lookupVariable returns the value of a placeholder like "VAR:-default".
+func lookupVariable(placeholder string, lookupEnv func(string) (string, bool)) (string, error) {
+	name, operand, operator := placeholder, "", ""
+
+	if i := strings.IndexAny(placeholder, ":-?"); i >= 0 {
+		name, operator = placeholder[:i], placeholder[i:]
+		for _, op := range []string{":-", ":?", "-", "?"} {
+			if strings.HasPrefix(operator, op) {
+				operator, operand = op, operator[len(op):]
+
+				break
+			}
+		}
+	}
+
+	value, set := lookupEnv(name)
+
+	switch operator {
+	case ":-":
+		if value == "" {
+			return operand, nil
+		}
+	case "-":
+		if !set {
+			return operand, nil
+		}
+	case ":?":
+		if value == "" {
+			return "", fmt.Errorf("environment variable %s is unset or empty: %s", name, operand)
+		}
+	case "?":
+		if !set {
+			return "", fmt.Errorf("environment variable %s is unset: %s", name, operand)
+		}
+	}
+
+	return value, nil
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/go-jsonnet"
+)
+
+var errJsonnetImport = errors.New("jsonnet imports are only allowed in config files")
+
+// WithJsonnetImportPaths is an option to add library paths to the imports of
+// Jsonnet configs. Imports relative to the importing file are resolved first.
+// Jsonnet of readers and sources, e.g. of a remote backend, must not import
+// files, so they cannot read local files into the config.
+func WithJsonnetImportPaths[T any](paths ...string) Option[T] {
+	return func(cl *loader[T]) {
+		cl.jsonnetImportPaths = append(cl.jsonnetImportPaths, paths...)
+		cl.rereadJsonnet()
+	}
+}
+
+// WithJsonnetExtVar is an option to set an external variable of Jsonnet
+// configs, read by std.extVar(name).
+func WithJsonnetExtVar[T any](name, value string) Option[T] {
+	return func(cl *loader[T]) {
+		if cl.jsonnetExtVars == nil {
+			cl.jsonnetExtVars = make(map[string]string)
+		}
+
+		cl.jsonnetExtVars[name] = value
+		cl.rereadJsonnet()
+	}
+}
+
+// rereadJsonnet reads the files given by earlier options again with the
+// changed Jsonnet options.
+func (c *loader[T]) rereadJsonnet() {
+	if c.hasConfigFiles() {
+		if err := c.readConfig(); err != nil {
+			c.logger.Error("Failed to read config from file", "error", err)
+		}
+	}
+}
+
+// decodeJsonnet evaluates Jsonnet without imports and external variables.
+func decodeJsonnet(data []byte) (map[string]any, error) {
+	vm := jsonnet.MakeVM()
+	vm.Importer(noImporter{})
+
+	return evaluateJsonnet(vm, "", data)
+}
+
+// evaluateJsonnet evaluates Jsonnet with the import paths and external
+// variables of the loader, imports are relative to filename. Without filename,
+// i.e. of readers and sources, imports fail.
+func (c *loader[T]) evaluateJsonnet(filename string, data []byte) (map[string]any, error) {
+	vm := jsonnet.MakeVM()
+	if filename == "" {
+		vm.Importer(noImporter{})
+	} else {
+		vm.Importer(&jsonnet.FileImporter{JPaths: c.jsonnetImportPaths})
+	}
+
+	for name, value := range c.jsonnetExtVars {
+		vm.ExtVar(name, value)
+	}
+
+	return evaluateJsonnet(vm, filename, data)
+}
+
+// evaluateJsonnet evaluates the Jsonnet snippet and decodes the resulting JSON.
+func evaluateJsonnet(vm *jsonnet.VM, filename string, data []byte) (map[string]any, error) {
+	output, err := vm.EvaluateAnonymousSnippet(filename, string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make(map[string]any)
+	if err := json.Unmarshal([]byte(output), &settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// noImporter rejects all Jsonnet imports.
+type noImporter struct{}
+
+func (noImporter) Import(_, importedPath string) (jsonnet.Contents, string, error) {
+	return jsonnet.Contents{}, "", fmt.Errorf("%w: %q", errJsonnetImport, importedPath)
+}
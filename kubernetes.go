@@ -0,0 +1,269 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"schneider.vip/config/internal/kube"
+)
+
+// WithKubernetesConfigMap is an option to load the config from a key of a
+// ConfigMap via the Kubernetes API server, without mounting it as a volume.
+// The ConfigMap is watched by StartWatcher and every change triggers a reload.
+// The format is derived from the key extension, e.g. "config.yml", unless
+// WithFormat is used. An empty key merges all entries of the ConfigMap as
+// settings. An empty namespace selects the namespace of the pod.
+// In a pod the service account is used, the pod needs RBAC permissions to get,
+// list and watch the ConfigMap. Outside of a cluster use WithEndpoint, e.g. with
+// "kubectl proxy", and WithBearerToken.
+func WithKubernetesConfigMap[T any](namespace, name, key string, opts ...RemoteOption) Option[T] {
+	return func(cl *loader[T]) {
+		cl.addSource(newKubernetesSource("configmaps", namespace, name, key, opts))
+	}
+}
+
+// WithKubernetesSecret is an option to load the config from a key of a Secret
+// via the Kubernetes API server, like WithKubernetesConfigMap. The Secret is
+// watched by StartWatcher, so rotated credentials are reloaded. An empty key
+// merges all entries of the Secret as settings, use WithSection to merge them
+// into a section, e.g. "databaseConfig" for the keys "user" and "password".
+func WithKubernetesSecret[T any](namespace, name, key string, opts ...RemoteOption) Option[T] {
+	return func(cl *loader[T]) {
+		cl.addSource(newKubernetesSource("secrets", namespace, name, key, opts))
+	}
+}
+
+// newKubernetesSource returns the source of a key of a Kubernetes resource,
+// or an errSource if there is no API server.
+func newKubernetesSource(resource, namespace, name, key string, opts []RemoteOption) Source {
+	o := newRemoteOptions(key, opts)
+
+	httpClient := o.httpClient
+	if httpClient == http.DefaultClient {
+		httpClient = nil
+	}
+
+	client, err := kube.NewClient(httpClient, o.endpoint, o.token)
+	if err != nil {
+		return errSource{err: err}
+	}
+
+	if namespace == "" {
+		namespace = kube.Namespace()
+	}
+
+	return &kubernetesSource{
+		client:    client,
+		resource:  resource,
+		namespace: namespace,
+		name:      name,
+		key:       key,
+		opts:      o,
+	}
+}
+
+// kubernetesSource reads and watches a key of a Kubernetes resource.
+type kubernetesSource struct {
+	client    *kube.Client
+	resource  string // "configmaps" or "secrets"
+	namespace string
+	name      string
+	key       string
+	opts      remoteOptions
+
+	mu              sync.Mutex
+	resourceVersion string // version of the last read, the watch starts from
+}
+
+// Read returns the value of the key, or all entries as JSON without key.
+func (s *kubernetesSource) Read() ([]byte, string, error) {
+	ctx, cancel := s.opts.readContext()
+	defer cancel()
+
+	data, resourceVersion, err := s.get(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.mu.Lock()
+	s.resourceVersion = resourceVersion
+	s.mu.Unlock()
+
+	if s.key == "" {
+		settings := make(map[string]any, len(data))
+		for key, value := range data {
+			settings[strings.ToLower(key)] = value
+		}
+
+		data, err := json.Marshal(nestSection(s.opts.section, settings))
+		if err != nil {
+			return nil, "", err
+		}
+
+		return data, "json", nil
+	}
+
+	value, ok := data[s.key]
+	if !ok {
+		return nil, "", fmt.Errorf("%w in %s %s/%s: %s", errKeyNotFound, s.resource, s.namespace, s.name, s.key)
+	}
+
+	return []byte(value), s.opts.configType, nil
+}
+
+// get returns the data and resource version of the resource.
+func (s *kubernetesSource) get(ctx context.Context) (map[string]string, string, error) {
+	req, err := s.client.NewRequest(ctx, s.path()+"/"+url.PathEscape(s.name))
+	if err != nil {
+		return nil, "", err
+	}
+
+	resp, err := doRequest(s.client.HTTPClient(), req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get %s %s/%s: %w", s.resource, s.namespace, s.name, err)
+	}
+	defer resp.Body.Close()
+
+	var object struct {
+		Metadata struct {
+			ResourceVersion string `json:"resourceVersion"`
+		} `json:"metadata"`
+		Data map[string]string `json:"data"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&object); err != nil {
+		return nil, "", fmt.Errorf("failed to decode %s %s/%s: %w", s.resource, s.namespace, s.name, err)
+	}
+
+	if s.resource == "secrets" {
+		for key, value := range object.Data {
+			decoded, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to decode secret %s/%s key %s: %w", s.namespace, s.name, key, err)
+			}
+
+			object.Data[key] = string(decoded)
+		}
+	}
+
+	return object.Data, object.Metadata.ResourceVersion, nil
+}
+
+// path returns the API path of the resource collection.
+func (s *kubernetesSource) path() string {
+	return "/api/v1/namespaces/" + url.PathEscape(s.namespace) + "/" + s.resource
+}
+
+// Watch watches the resource, a broken watch is retried in the poll interval
+// and closed streams are restarted with exponential backoff.
+func (s *kubernetesSource) Watch(ctx context.Context) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+
+	go func() {
+		defer close(changes)
+
+		backoff := ExponentialBackoff(100*time.Millisecond, s.opts.pollInterval)
+
+		for attempt := 1; ; attempt++ {
+			started := time.Now()
+
+			err := s.watch(ctx, changes)
+			if errors.Is(err, io.EOF) {
+				// the API server closes watches after a timeout, streams which
+				// end right away, e.g. by a proxy, are retried with backoff
+				if time.Since(started) > s.opts.pollInterval {
+					attempt = 1
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(backoff(attempt)):
+				}
+
+				continue
+			}
+
+			attempt = 0
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(s.opts.pollInterval):
+				notify(changes)
+			}
+		}
+	}()
+
+	return changes
+}
+
+// watch sends a change for every event of the resource, until the stream
+// ends.
+func (s *kubernetesSource) watch(ctx context.Context, changes chan<- struct{}) error {
+	s.mu.Lock()
+	resourceVersion := s.resourceVersion
+	s.mu.Unlock()
+
+	query := url.Values{
+		"watch":         {"true"},
+		"fieldSelector": {"metadata.name=" + s.name},
+	}
+
+	if resourceVersion != "" {
+		query.Set("resourceVersion", resourceVersion)
+	}
+
+	req, err := s.client.NewRequest(ctx, s.path()+"?"+query.Encode())
+	if err != nil {
+		return err
+	}
+
+	resp, err := doRequest(s.client.HTTPClient(), req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(resp.Body)
+
+	for {
+		var event struct {
+			Type   string `json:"type"`
+			Object struct {
+				Metadata struct {
+					ResourceVersion string `json:"resourceVersion"`
+				} `json:"metadata"`
+				Message string `json:"message"`
+			} `json:"object"`
+		}
+
+		if err := decoder.Decode(&event); err != nil {
+			return err
+		}
+
+		if event.Type == "ERROR" {
+			// e.g. the resource version is too old, the retry reads again
+			s.mu.Lock()
+			s.resourceVersion = ""
+			s.mu.Unlock()
+
+			return fmt.Errorf("kubernetes watch failed: %s", event.Object.Message)
+		}
+
+		s.mu.Lock()
+		s.resourceVersion = event.Object.Metadata.ResourceVersion
+		s.mu.Unlock()
+
+		notify(changes)
+	}
+}
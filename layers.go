@@ -0,0 +1,160 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WithSources is an option to layer several sources in the given order of
+// precedence, every source overrides the settings of the sources before it.
+// The sources are read and deep-merged on load and on every reload, on top of
+// the config files of the loader. Environment variables bound by the loader
+// still take precedence over all sources.
+//
+//	config.WithSources[GlobalConfig](
+//		config.FileSource("config.yml"),
+//		config.EtcdSource([]string{"http://etcd:2379"}, "/myapp/config.yml"),
+//		config.EnvSource("MYAPP"),
+//	)
+func WithSources[T any](sources ...Source) Option[T] {
+	return func(cl *loader[T]) {
+		for _, s := range sources {
+			cl.addSource(s)
+		}
+	}
+}
+
+// FileSource returns a source of the config file at path, the format is
+// derived from the file extension. The file is watched for changes.
+func FileSource(path string) Source {
+	return &fileSource{path: path}
+}
+
+// fileSource reads and watches a config file.
+type fileSource struct {
+	path string
+}
+
+// Read returns the content of the file.
+func (s *fileSource) Read() ([]byte, string, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, configTypeFromPath(s.path), nil
+}
+
+// String returns the path of the file.
+func (s *fileSource) String() string {
+	return s.path
+}
+
+// Watch watches the directory of the file and sends a change for every event
+// of the file, including atomic replacements by rename.
+func (s *fileSource) Watch(ctx context.Context) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		close(changes)
+
+		return changes
+	}
+
+	path := filepath.Clean(s.path)
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		close(changes)
+
+		return changes
+	}
+
+	go func() {
+		defer close(changes)
+		defer watcher.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				if filepath.Clean(event.Name) == path {
+					notify(changes)
+				}
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+
+	return changes
+}
+
+// EnvSource returns a source of the environment variables with the prefix,
+// e.g. "MYAPP" for "MYAPP_DATABASECONFIG_HOST". The prefix is removed and the
+// names are split into nested keys at "_". An empty prefix selects all
+// variables. The environment is not watched.
+func EnvSource(prefix string) Source {
+	return &envSource{prefix: prefix}
+}
+
+// envSource reads environment variables.
+type envSource struct {
+	prefix string
+}
+
+// Read returns the variables as JSON.
+func (s *envSource) Read() ([]byte, string, error) {
+	settings := make(map[string]any)
+
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+
+		if s.prefix != "" {
+			var ok bool
+			if name, ok = strings.CutPrefix(name, s.prefix+keyDelimiter); !ok {
+				continue
+			}
+		}
+
+		if name != "" {
+			setPath(settings, strings.Split(strings.ToLower(name), keyDelimiter), value)
+		}
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, "json", nil
+}
+
+// String describes the variables of the source.
+func (s *envSource) String() string {
+	if s.prefix == "" {
+		return "environment"
+	}
+
+	return "environment " + s.prefix + keyDelimiter + "*"
+}
+
+// Watch returns a closed channel, the environment is not watched.
+func (s *envSource) Watch(context.Context) <-chan struct{} {
+	changes := make(chan struct{})
+	close(changes)
+
+	return changes
+}
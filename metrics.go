@@ -0,0 +1,62 @@
+package config
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	reloadsDesc = prometheus.NewDesc("config_reloads_total",
+		"Number of config reload attempts.", nil, nil)
+	reloadFailuresDesc = prometheus.NewDesc("config_reload_failures_total",
+		"Number of failed config reloads.", nil, nil)
+	lastSuccessDesc = prometheus.NewDesc("config_last_success_timestamp_seconds",
+		"Unix time of the last successful config parse.", nil, nil)
+	parseDurationDesc = prometheus.NewDesc("config_parse_duration_seconds",
+		"Duration of the last config parse.", nil, nil)
+	infoDesc = prometheus.NewDesc("config_info",
+		"Checksum of the effective config, the value is always 1.", []string{"checksum"}, nil)
+)
+
+// WithPrometheus is an option to register a collector of the config metrics
+// with the registerer: reload attempts and failures, the time of the last
+// successful parse, the duration of the last parse and the checksum of the
+// effective config. Use prometheus.WrapRegistererWith to add labels for
+// several loaders in one process.
+//
+//	config.WithPrometheus[GlobalConfig](prometheus.DefaultRegisterer)
+func WithPrometheus[T any](registerer prometheus.Registerer) Option[T] {
+	return func(cl *loader[T]) {
+		if err := registerer.Register(&collector{stats: &cl.stats}); err != nil {
+			cl.logger.Error("Failed to register config metrics", "error", err)
+		}
+	}
+}
+
+// collector is a prometheus.Collector of the statistics of a loader.
+type collector struct {
+	stats *loadStats
+}
+
+// Describe sends the descriptors of the metrics.
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- reloadsDesc
+	ch <- reloadFailuresDesc
+	ch <- lastSuccessDesc
+	ch <- parseDurationDesc
+	ch <- infoDesc
+}
+
+// Collect sends the current metrics.
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.stats.snapshot()
+
+	ch <- prometheus.MustNewConstMetric(reloadsDesc, prometheus.CounterValue, float64(stats.reloads))
+	ch <- prometheus.MustNewConstMetric(reloadFailuresDesc, prometheus.CounterValue, float64(stats.reloadFailures))
+	ch <- prometheus.MustNewConstMetric(parseDurationDesc, prometheus.GaugeValue, stats.parseDuration.Seconds())
+
+	if !stats.lastSuccess.IsZero() {
+		ch <- prometheus.MustNewConstMetric(lastSuccessDesc, prometheus.GaugeValue,
+			float64(stats.lastSuccess.UnixNano())/1e9)
+		ch <- prometheus.MustNewConstMetric(infoDesc, prometheus.GaugeValue, 1, stats.checksum)
+	}
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// versionKey is the key of the schema version of the config.
+const versionKey = "version"
+
+var (
+	errUnsupportedVersion = errors.New("unsupported config version")
+	errNoMigratedSettings = errors.New("migration returned no settings")
+)
+
+// migration upgrades the settings of a schema version to another.
+type migration struct {
+	from, to int
+	fn       func(map[string]any) map[string]any
+}
+
+// WithMigration is an option to upgrade configs of the schema version from to
+// the version to, the version is the "version" key of the config or section.
+// Migrations are chained up to the latest version of all migrations, configs
+// without a version are considered up to date. The parse fails for newer
+// versions, for versions without a migration path and if fn returns nil.
+func WithMigration[T any](from, to int, fn func(map[string]any) map[string]any) Option[T] {
+	return func(cl *loader[T]) {
+		cl.migrations = append(cl.migrations, migration{from: from, to: to, fn: fn})
+	}
+}
+
+// migrate upgrades the settings to the latest schema version.
+func (c *loader[T]) migrate(settings map[string]any) (map[string]any, error) {
+	if len(c.migrations) == 0 {
+		return settings, nil
+	}
+
+	value, ok := settings[versionKey]
+	if !ok {
+		return settings, nil
+	}
+
+	version, err := strconv.Atoi(fmt.Sprint(value))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errUnsupportedVersion, value)
+	}
+
+	latest := 0
+	for _, m := range c.migrations {
+		latest = max(latest, m.to)
+	}
+
+	if version > latest {
+		return nil, fmt.Errorf("%w: %d is newer than %d", errUnsupportedVersion, version, latest)
+	}
+
+	for version < latest {
+		i := c.migrationFrom(version)
+		if i < 0 {
+			return nil, fmt.Errorf("%w: no migration from %d to %d", errUnsupportedVersion, version, latest)
+		}
+
+		c.logger.Info("Migrating config", "from", version, "to", c.migrations[i].to)
+
+		m := c.migrations[i]
+
+		settings = m.fn(settings)
+		if settings == nil {
+			return nil, fmt.Errorf("%w: from %d to %d", errNoMigratedSettings, m.from, m.to)
+		}
+
+		version = m.to
+		settings[versionKey] = version
+	}
+
+	return settings, nil
+}
+
+// migrationFrom returns the index of the migration from the version to the
+// highest version, -1 if there is none.
+func (c *loader[T]) migrationFrom(version int) int {
+	index := -1
+
+	for i, m := range c.migrations {
+		if m.from == version && m.to > version && (index < 0 || m.to > c.migrations[index].to) {
+			index = i
+		}
+	}
+
+	return index
+}
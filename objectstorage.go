@@ -0,0 +1,102 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"schneider.vip/config/internal/aws"
+	"schneider.vip/config/internal/gcp"
+)
+
+var errUnsupportedScheme = errors.New("unsupported object storage scheme, use s3:// or gs://")
+
+// WithObjectStorage is an option to load the config from an object of Amazon S3
+// ("s3://bucket/app/config.yml") or Google Cloud Storage ("gs://bucket/app/config.yml").
+// The requests are conditional with the object ETag, StartWatcher polls the
+// object in the poll interval and reloads only if it changed.
+// The format is derived from the object extension unless WithFormat is used.
+// Credentials are discovered like the AWS and Google Cloud SDKs do. WithEndpoint
+// overrides the service endpoint, e.g. for MinIO or a storage emulator.
+func WithObjectStorage[T any](rawURL string, opts ...RemoteOption) Option[T] {
+	return func(cl *loader[T]) {
+		s, err := newObjectStorageSource(rawURL, opts)
+		if err != nil {
+			cl.addSource(errSource{err: err})
+
+			return
+		}
+
+		cl.addSource(s)
+	}
+}
+
+// newObjectStorageSource returns a URL source of the object, authorized for
+// the storage service.
+func newObjectStorageSource(rawURL string, opts []RemoteOption) (*urlSource, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket, object := u.Host, strings.TrimPrefix(u.Path, "/")
+	o := newRemoteOptions(object, opts)
+	s := &urlSource{opts: o}
+
+	switch u.Scheme {
+	case "s3":
+		client, err := aws.NewClient(o.httpClient, o.region, o.endpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		if o.endpoint != "" {
+			s.url = strings.TrimSuffix(o.endpoint, "/") + "/" + bucket + "/" + escapeObject(object)
+		} else {
+			s.url = fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, client.Region, escapeObject(object))
+		}
+
+		s.authorize = func(req *http.Request) error {
+			return client.Sign(req.Context(), req, nil, "s3")
+		}
+	case "gs":
+		endpoint := "https://storage.googleapis.com"
+		if o.endpoint != "" {
+			endpoint = strings.TrimSuffix(o.endpoint, "/")
+		}
+
+		s.url = endpoint + "/" + bucket + "/" + escapeObject(object)
+
+		if o.token != "" {
+			s.authorize = o.authorize
+		} else {
+			tokens := gcp.NewTokenProvider(o.httpClient)
+			s.authorize = func(req *http.Request) error {
+				token, err := tokens.Token(req.Context())
+				if err != nil {
+					return err
+				}
+
+				req.Header.Set("Authorization", "Bearer "+token)
+
+				return nil
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnsupportedScheme, rawURL)
+	}
+
+	return s, nil
+}
+
+// escapeObject escapes the segments of an object name for a URL path.
+func escapeObject(object string) string {
+	segments := strings.Split(object, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package config
+
+import "io/fs"
+
+// fileOwner reports no owner, files have no uid on this platform.
+func fileOwner(fs.FileInfo) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package config
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// fileOwner returns the uid of the owner of the file.
+func fileOwner(info fs.FileInfo) (int, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+
+	return int(stat.Uid), true
+}
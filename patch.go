@@ -0,0 +1,115 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+// ApplyPatch applies a JSON merge patch (RFC 7386) or, if the patch is an
+// array, a JSON patch (RFC 6902) to the current config, e.g.
+// {"databaseConfig":{"port":6543}}. The changed values are set like by Set,
+// removed values drop the values set at runtime, so the values of the sources
+// apply again. If the config is invalid with the patch, nothing is changed.
+func (c *loader[T]) ApplyPatch(patch []byte) error {
+	config := c.config.Load()
+	if config == nil {
+		return errNotLoaded
+	}
+
+	original, err := json.Marshal(structSettings(reflect.ValueOf(config)))
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	patched, err := applyJSONPatch(original, patch)
+	if err != nil {
+		return fmt.Errorf("failed to apply patch: %w", err)
+	}
+
+	values, err := patchedValues(original, patched)
+	if err != nil {
+		return fmt.Errorf("failed to apply patch: %w", err)
+	}
+
+	overrides := make(map[string]any, len(values))
+
+	for key, value := range values {
+		if !isFieldKey[T](key) {
+			return fmt.Errorf("%w: %q", errUnknownField, key)
+		}
+
+		if c.subSection != "" {
+			key = strings.ToLower(c.subSection) + keyDelimiter + key
+		}
+
+		overrides[key] = value
+	}
+
+	return c.override(triggerPatch, overrides)
+}
+
+// applyJSONPatch applies the merge patch or the JSON patch to the document.
+func applyJSONPatch(doc, patch []byte) ([]byte, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(patch), []byte("[")) {
+		return jsonpatch.MergePatch(doc, patch)
+	}
+
+	operations, err := jsonpatch.DecodePatch(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	return operations.Apply(doc)
+}
+
+// patchedValues returns the values of the keys which differ between the
+// documents, removed keys are nil.
+func patchedValues(original, patched []byte) (map[string]any, error) {
+	var before, after map[string]any
+
+	if err := json.Unmarshal(original, &before); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(patched, &after); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]any)
+
+	flattenSettings(after, "", func(key string, value any) {
+		values[key] = value
+	})
+
+	flattenSettings(before, "", func(key string, value any) {
+		if after, ok := values[key]; !ok {
+			values[key] = nil
+		} else if reflect.DeepEqual(after, value) {
+			delete(values, key)
+		}
+	})
+
+	return values, nil
+}
+
+// isFieldKey reports whether the key or a key it is nested in, e.g. of a map,
+// is the key of a field of T.
+func isFieldKey[T any](key string) bool {
+	for {
+		if _, ok := fieldByKey[T](key); ok {
+			return true
+		}
+
+		i := strings.LastIndex(key, keyDelimiter)
+		if i < 0 {
+			return false
+		}
+
+		key = key[:i]
+	}
+}
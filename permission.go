@@ -0,0 +1,84 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+var errInsecureFile = errors.New("insecure config file")
+
+// WithPermissionCheck is an option to reject config files with permissions
+// beyond max, e.g. 0o600 for files with secrets, or owned by another user than
+// the current user or root, like ssh does for key files. New fails like for
+// invalid configs and reloads keep the current config. The owner is not
+// checked on Windows.
+func WithPermissionCheck[T any](max os.FileMode) Option[T] {
+	return func(cl *loader[T]) {
+		cl.permissionCheck = true
+		cl.permissionWarning = false
+		cl.maxFileMode = max.Perm()
+
+		// files given by earlier options are read again checked
+		if cl.hasConfigFiles() {
+			if err := cl.readConfig(); err != nil {
+				cl.logger.Error("Failed to read config from file", "error", err)
+			}
+		}
+	}
+}
+
+// WithPermissionWarning is an option to check the permissions and the owner
+// of config files like WithPermissionCheck, but insecure files are only logged
+// and read anyway, e.g. while the permissions of deployments are tightened.
+func WithPermissionWarning[T any](max os.FileMode) Option[T] {
+	return func(cl *loader[T]) {
+		cl.permissionCheck = true
+		cl.permissionWarning = true
+		cl.maxFileMode = max.Perm()
+
+		// files given by earlier options are read again to log them
+		if cl.hasConfigFiles() {
+			if err := cl.readConfig(); err != nil {
+				cl.logger.Error("Failed to read config from file", "error", err)
+			}
+		}
+	}
+}
+
+// checkPermissions checks the permissions and the owner of the config file,
+// insecure files are only logged by WithPermissionWarning.
+func (c *loader[T]) checkPermissions(path string) error {
+	if !c.permissionCheck {
+		return nil
+	}
+
+	err := c.checkFileMode(path)
+	if err != nil && c.permissionWarning && errors.Is(err, errInsecureFile) {
+		c.logger.Error("Insecure config file", "error", err)
+
+		return nil
+	}
+
+	return err
+}
+
+// checkFileMode returns an error if the permissions or the owner of the config
+// file are insecure.
+func (c *loader[T]) checkFileMode(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if perm := info.Mode().Perm(); perm&^c.maxFileMode != 0 {
+		return fmt.Errorf("%w: permissions %04o of %s are too open, at most %04o are allowed",
+			errInsecureFile, perm, path, c.maxFileMode)
+	}
+
+	if uid, ok := fileOwner(info); ok && uid != 0 && uid != os.Getuid() {
+		return fmt.Errorf("%w: %s is owned by uid %d, expected uid %d or root", errInsecureFile, path, uid, os.Getuid())
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"slices"
+	"strings"
+)
+
+// Layer is a layer of config values, whose precedence is set by
+// WithPrecedence.
+type Layer int
+
+// The layers of config values.
+const (
+	Flags    Layer = iota // command-line flags
+	Env                   // environment variables
+	File                  // config files, readers and profiles
+	Remote                // sources like of WithSource
+	Defaults              // defaults, e.g. of WithDefaultsFromReader
+)
+
+// defaultPrecedence is the precedence of the layers like for viper.
+var defaultPrecedence = []Layer{Flags, Env, Remote, File, Defaults}
+
+// layerValue is a value of a key in a layer.
+type layerValue struct {
+	value any
+	info  SourceInfo
+}
+
+// WithPrecedence is an option to set the precedence of the layers, earlier
+// layers override later ones, e.g. WithPrecedence(Flags, File, Env) for
+// configs files, which must not be overridden by the environment. Layers which
+// are not given have the lowest precedence, in the default order of Flags, Env,
+// Remote, File and Defaults.
+func WithPrecedence[T any](layers ...Layer) Option[T] {
+	return func(cl *loader[T]) {
+		precedence := slices.Clone(layers)
+
+		for _, layer := range defaultPrecedence {
+			if !slices.Contains(precedence, layer) {
+				precedence = append(precedence, layer)
+			}
+		}
+
+		if len(precedence) != len(defaultPrecedence) {
+			cl.logger.Error("Failed to set precedence, layers must be unique and valid", "layers", layers)
+
+			return
+		}
+
+		cl.precedence = precedence
+	}
+}
+
+// layerOf returns the layer of the kind of source.
+func layerOf(kind SourceKind) Layer {
+	switch kind {
+	case KindFlag:
+		return Flags
+	case KindEnv:
+		return Env
+	case KindSource:
+		return Remote
+	case KindDefault:
+		return Defaults
+	default:
+		return File
+	}
+}
+
+// setOrigin records the source of the key and its value in the layer of the
+// source.
+func (c *loader[T]) setOrigin(key string, value any, info SourceInfo) {
+	if c.origins == nil {
+		c.origins = make(map[string]SourceInfo)
+	}
+
+	c.origins[key] = info
+	c.setLayerValue(key, value, info)
+}
+
+// setLayerValue records the value of the key in the layer of the source.
+func (c *loader[T]) setLayerValue(key string, value any, info SourceInfo) {
+	if c.layers == nil {
+		c.layers = make(map[Layer]map[string]layerValue)
+	}
+
+	layer := layerOf(info.Kind)
+	if c.layers[layer] == nil {
+		c.layers[layer] = make(map[string]layerValue)
+	}
+
+	c.layers[layer][key] = layerValue{value: value, info: info}
+}
+
+// resetOrigins forgets the sources of the config layer, before the config is
+// read again.
+func (c *loader[T]) resetOrigins() {
+	c.origins = make(map[string]SourceInfo)
+
+	for _, layer := range []Layer{File, Remote} {
+		delete(c.layers, layer)
+	}
+}
+
+// allSettings returns the settings of all keys, the values are resolved by
+// the precedence of WithPrecedence.
+func (c *loader[T]) allSettings() map[string]any {
+	if c.precedence == nil {
+		return c.viper.AllSettings()
+	}
+
+	settings := make(map[string]any)
+	envNames := c.envTagNames()
+
+	for _, key := range c.viper.AllKeys() {
+		value, _, ok := c.resolve(key, envNames)
+		if !ok {
+			value = c.viper.Get(key)
+		}
+
+		if value != nil {
+			setPath(settings, strings.Split(key, keyDelimiter), value)
+		}
+	}
+
+	return settings
+}
+
+// resolve returns the value of the key of the layer with the highest
+// precedence, which sets the key, and its source.
+func (c *loader[T]) resolve(key string, envNames map[string]string) (any, SourceInfo, bool) {
+	if value, ok := c.overrides[key]; ok {
+		return value, SourceInfo{Kind: KindRuntime}, true
+	}
+
+	for _, layer := range c.precedence {
+		switch layer {
+		case Flags:
+			if name, ok := c.changedFlag(key); ok {
+				return c.viper.Get(key), SourceInfo{Kind: KindFlag, Name: name}, true
+			}
+		case Env:
+			if name, ok := c.envVariable(key, envNames); ok {
+				return c.getEnv(name), SourceInfo{Kind: KindEnv, Name: name}, true
+			}
+		default:
+			if v, ok := c.layers[layer][key]; ok {
+				return v.value, v.info, true
+			}
+		}
+	}
+
+	return nil, SourceInfo{}, false
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// profileEnv is the environment variable used if no profile is given.
+const profileEnv = "APP_ENV"
+
+// WithProfile is an option to overlay a profile on top of the config files. The
+// "profiles.<profile>" section of the config files is merged first, then a
+// profile file next to the config file, e.g. config.prod.yml for config.yml.
+// The sources are merged on top of the profile.
+// If profile is empty, the profile is taken from the APP_ENV environment variable.
+func WithProfile[T any](profile string) Option[T] {
+	return func(cl *loader[T]) {
+		if profile == "" {
+			profile = os.Getenv(profileEnv)
+		}
+
+		cl.profile = profile
+	}
+}
+
+// applyProfile merges the profile section and the profile file into the config
+// files, before the sources are merged.
+func (c *loader[T]) applyProfile() error {
+	if c.profile == "" {
+		return nil
+	}
+
+	// Get keeps the names of the profiles, AllSettings and Sub split them at
+	// the key delimiter, e.g. "prod_eu" into "prod" and "eu"
+	profiles, _ := c.viper.Get("profiles").(map[string]any)
+	if settings, ok := profiles[strings.ToLower(c.profile)].(map[string]any); ok {
+		c.setOrigins(settings, SourceInfo{Kind: KindProfile, Name: c.profile})
+
+		if err := c.viper.MergeConfigMap(settings); err != nil {
+			return err
+		}
+	}
+
+	profileFile := c.profileFile()
+	if profileFile == "" {
+		return nil
+	}
+
+	if _, err := os.Stat(profileFile); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return c.mergeFile(profileFile)
+}
+
+// profileFile returns the profile file of the (first) config file.
+func (c *loader[T]) profileFile() string {
+	if c.profile == "" || c.configDir != "" {
+		return ""
+	}
+
+	baseFile := c.viper.ConfigFileUsed()
+	if len(c.configFiles) > 0 {
+		baseFile = c.configFiles[0]
+	}
+
+	if baseFile == "" {
+		return ""
+	}
+
+	// the profile of config.yml.age is config.<profile>.yml.age
+	name := strings.TrimSuffix(baseFile, ".age")
+	ext := filepath.Ext(name)
+
+	return strings.TrimSuffix(name, ext) + "." + c.profile + ext + strings.TrimPrefix(baseFile, name)
+}
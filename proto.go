@@ -0,0 +1,167 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var (
+	protoMessageType = reflect.TypeOf((*proto.Message)(nil)).Elem()
+
+	// protoJSONTypes are the well-known types with a special JSON mapping.
+	protoJSONTypes = map[protoreflect.FullName]bool{
+		"google.protobuf.Any":         true,
+		"google.protobuf.Duration":    true,
+		"google.protobuf.Timestamp":   true,
+		"google.protobuf.FieldMask":   true,
+		"google.protobuf.Struct":      true,
+		"google.protobuf.Value":       true,
+		"google.protobuf.ListValue":   true,
+		"google.protobuf.BoolValue":   true,
+		"google.protobuf.BytesValue":  true,
+		"google.protobuf.DoubleValue": true,
+		"google.protobuf.FloatValue":  true,
+		"google.protobuf.Int32Value":  true,
+		"google.protobuf.Int64Value":  true,
+		"google.protobuf.StringValue": true,
+		"google.protobuf.UInt32Value": true,
+		"google.protobuf.UInt64Value": true,
+	}
+
+	errTextprotoType = errors.New("text format configs require the config type to be a protobuf message")
+)
+
+// protoMessageHook decodes protobuf messages of the config by protojson, which
+// supports oneofs, enums by name and the well-known types like Duration and
+// Timestamp. Keys are matched case-insensitively to the JSON or field names.
+func protoMessageHook(_ reflect.Type, to reflect.Type, data any) (any, error) {
+	if _, ok := data.(proto.Message); ok || data == nil {
+		return data, nil
+	}
+
+	messageType := to
+	if to.Kind() != reflect.Pointer {
+		messageType = reflect.PointerTo(to)
+	}
+
+	if !messageType.Implements(protoMessageType) {
+		return data, nil
+	}
+
+	message, _ := reflect.New(messageType.Elem()).Interface().(proto.Message)
+
+	encoded, err := json.Marshal(protoJSONNames(data, message.ProtoReflect().Descriptor()))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(encoded, message); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", message.ProtoReflect().Descriptor().FullName(), err)
+	}
+
+	return message, nil
+}
+
+// protoJSONNames returns a copy of the lower-cased settings of viper with the
+// JSON names of the message fields. The JSON mapping of the well-known types
+// is kept.
+func protoJSONNames(data any, descriptor protoreflect.MessageDescriptor) any {
+	settings, ok := data.(map[string]any)
+	if !ok || protoJSONTypes[descriptor.FullName()] {
+		return data
+	}
+
+	renamed := make(map[string]any, len(settings))
+
+	for key, value := range settings {
+		field := protoField(descriptor, key)
+		if field == nil {
+			renamed[key] = value
+
+			continue
+		}
+
+		switch {
+		case field.IsMap() && field.MapValue().Message() != nil:
+			if entries, ok := value.(map[string]any); ok {
+				values := make(map[string]any, len(entries))
+				for name, entry := range entries {
+					values[name] = protoJSONNames(entry, field.MapValue().Message())
+				}
+
+				value = values
+			}
+		case field.IsList() && field.Message() != nil:
+			if elements, ok := value.([]any); ok {
+				values := make([]any, len(elements))
+				for i, element := range elements {
+					values[i] = protoJSONNames(element, field.Message())
+				}
+
+				value = values
+			}
+		case field.Message() != nil && !field.IsMap():
+			value = protoJSONNames(value, field.Message())
+		}
+
+		renamed[field.JSONName()] = value
+	}
+
+	return renamed
+}
+
+// protoField returns the field of the message by its JSON or field name,
+// matched case-insensitively. Returns nil for unknown fields.
+func protoField(descriptor protoreflect.MessageDescriptor, key string) protoreflect.FieldDescriptor {
+	fields := descriptor.Fields()
+
+	for i := range fields.Len() {
+		field := fields.Get(i)
+		if strings.EqualFold(key, field.JSONName()) || strings.EqualFold(key, string(field.Name())) {
+			return field
+		}
+	}
+
+	return nil
+}
+
+// decodeTextproto fails, the protobuf text format can only be decoded into the
+// message of the config type, see loader.decodeTextproto.
+func decodeTextproto([]byte) (map[string]any, error) {
+	return nil, errTextprotoType
+}
+
+// decodeTextproto decodes the protobuf text format into the message of the
+// config type.
+func (c *loader[T]) decodeTextproto(data []byte) (map[string]any, error) {
+	var config T
+
+	message, ok := any(&config).(proto.Message)
+	if !ok {
+		return nil, errTextprotoType
+	}
+
+	if err := prototext.Unmarshal(data, message); err != nil {
+		return nil, err
+	}
+
+	encoded, err := protojson.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make(map[string]any)
+	if err := json.Unmarshal(encoded, &settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
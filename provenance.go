@@ -0,0 +1,180 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"strings"
+)
+
+// SourceKind is the kind of source which supplied a config value.
+type SourceKind string
+
+// The kinds of sources in the order of precedence, every kind overrides the
+// kinds before it.
+const (
+	KindDefault SourceKind = "default" // defaults, e.g. of WithDefaultsFromReader
+	KindFile    SourceKind = "file"    // config file
+	KindReader  SourceKind = "reader"  // config of WithConfigReader
+	KindSource  SourceKind = "source"  // source like of WithSource or a remote store
+	KindProfile SourceKind = "profile" // profiles section of the config
+	KindEnv     SourceKind = "env"     // environment variable
+	KindFlag    SourceKind = "flag"    // command-line flag
+	KindRuntime SourceKind = "runtime" // value of Set
+)
+
+// SourceInfo describes the source of a config value.
+type SourceInfo struct {
+	Kind SourceKind
+	// Name is the path of the file, the name of the environment variable,
+	// the profile or the description of the source.
+	Name string
+	// Alias is the key of WithAlias, which set the value.
+	Alias string
+}
+
+// String returns the kind and the name, e.g. "file config.yml", and the alias,
+// e.g. "file config.yml via alias db_host".
+func (s SourceInfo) String() string {
+	str := string(s.Kind)
+	if s.Name != "" {
+		str += " " + s.Name
+	}
+
+	if s.Alias != "" {
+		str += " via alias " + s.Alias
+	}
+
+	return str
+}
+
+// Provenance returns the source of the effective value of every key of the
+// last parsed config, the keys are nested with "_" like for viper, e.g.
+// "databaseconfig_host".
+func (c *loader[T]) Provenance() map[string]SourceInfo {
+	provenance := c.provenance.Load()
+	if provenance == nil {
+		return nil
+	}
+
+	return maps.Clone(*provenance)
+}
+
+// setOrigins records the source of the keys of settings, which are merged
+// into the config.
+func (c *loader[T]) setOrigins(settings map[string]any, info SourceInfo) {
+	flattenSettings(settings, "", func(key string, value any) {
+		c.setOrigin(key, value, info)
+	})
+}
+
+// storeProvenance stores the sources of the keys of the config, flags and
+// environment variables override the recorded sources like for viper or in
+// the precedence of WithPrecedence, keys without a recorded source are
+// defaults.
+func (c *loader[T]) storeProvenance() {
+	provenance := make(map[string]SourceInfo)
+	envNames := c.envTagNames()
+
+	for _, key := range c.viper.AllKeys() {
+		if !c.viper.IsSet(key) {
+			continue
+		}
+
+		if _, ok := c.overrides[key]; ok {
+			provenance[key] = SourceInfo{Kind: KindRuntime}
+		} else if c.precedence != nil {
+			if _, info, ok := c.resolve(key, envNames); ok {
+				provenance[key] = info
+			} else {
+				provenance[key] = SourceInfo{Kind: KindDefault}
+			}
+		} else if name, ok := c.changedFlag(key); ok {
+			provenance[key] = SourceInfo{Kind: KindFlag, Name: name}
+		} else if name, ok := c.envVariable(key, envNames); ok {
+			provenance[key] = SourceInfo{Kind: KindEnv, Name: name}
+		} else if info, ok := c.origins[key]; ok {
+			provenance[key] = info
+		} else {
+			provenance[key] = SourceInfo{Kind: KindDefault}
+		}
+	}
+
+	c.provenance.Store(&provenance)
+}
+
+// envTagNames returns the environment variables of the fields with env tags
+// by key.
+func (c *loader[T]) envTagNames() map[string]string {
+	names := make(map[string]string)
+
+	_ = walkFields(reflect.TypeFor[T](), strings.ToLower(c.subSection), func(key string, field reflect.StructField) error {
+		if name := field.Tag.Get(envTag); name != "" && !isNoEnv(field) {
+			names[key] = name
+		}
+
+		return nil
+	})
+
+	return names
+}
+
+// envVariable returns the name of the environment variable which sets the
+// key, bound by an env tag or automatically.
+func (c *loader[T]) envVariable(key string, envNames map[string]string) (string, bool) {
+	if name, ok := envNames[key]; ok && c.getEnv(name) != "" {
+		return name, true
+	}
+
+	name := c.automaticEnvName(key)
+
+	return name, name != "" && c.getEnv(name) != ""
+}
+
+// automaticEnvName returns the name of the environment variable of the key
+// of AutomaticEnv, empty if there is none, e.g. for sections.
+func (c *loader[T]) automaticEnvName(key string) string {
+	if c.disableAutomaticEnv || c.subSection != "" {
+		return ""
+	}
+
+	name := strings.ToUpper(key)
+	if prefix := c.viper.GetEnvPrefix(); prefix != "" {
+		name = strings.ToUpper(prefix) + "_" + name
+	}
+
+	if c.envReplacer != nil {
+		name = c.envReplacer.Replace(name)
+	}
+
+	return name
+}
+
+// flattenSettings calls fn for every value of the nested settings with its
+// key, nested keys are joined with the key delimiter.
+func flattenSettings(settings map[string]any, prefix string, fn func(key string, value any)) {
+	for key, value := range settings {
+		key = strings.ToLower(key)
+		if prefix != "" {
+			key = prefix + keyDelimiter + key
+		}
+
+		if nested, ok := value.(map[string]any); ok && len(nested) > 0 {
+			flattenSettings(nested, key, fn)
+
+			continue
+		}
+
+		fn(key, value)
+	}
+}
+
+// sourceName describes the source, sources implementing fmt.Stringer
+// describe themselves.
+func sourceName(i int, s Source) string {
+	if stringer, ok := s.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+
+	return fmt.Sprintf("#%d (%T)", i+1, s)
+}
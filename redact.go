@@ -0,0 +1,154 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// redacted replaces the values of secret fields.
+const redacted = "[REDACTED]"
+
+// isSecret reports whether the field is tagged with secret:"true" or
+// redact:"true", the values of such fields are masked in errors and logs of
+// the loader.
+func isSecret(field reflect.StructField) bool {
+	for _, tag := range []string{"secret", "redact"} {
+		if secret, _ := strconv.ParseBool(field.Tag.Get(tag)); secret {
+			return true
+		}
+	}
+
+	return false
+}
+
+// secretKeys returns the config keys of the secret fields of the config type.
+func (c *loader[T]) secretKeys() []string {
+	var keys []string
+
+	_ = walkFields(reflect.TypeFor[T](), strings.ToLower(c.subSection), func(key string, field reflect.StructField) error {
+		if isSecret(field) {
+			keys = append(keys, key)
+		}
+
+		return nil
+	})
+
+	return keys
+}
+
+// secretValues returns the current values of the secret fields as strings.
+func (c *loader[T]) secretValues() []string {
+	var values []string
+
+	for _, key := range c.secretKeys() {
+		values = appendValues(values, c.viper.Get(key))
+	}
+
+	return values
+}
+
+// appendValues appends the scalar values of value, nested in maps and slices.
+func appendValues(values []string, value any) []string {
+	switch value := value.(type) {
+	case nil:
+		return values
+	case map[string]any:
+		for _, v := range value {
+			values = appendValues(values, v)
+		}
+
+		return values
+	case []any:
+		for _, v := range value {
+			values = appendValues(values, v)
+		}
+
+		return values
+	}
+
+	if s := fmt.Sprint(value); s != "" {
+		values = append(values, s)
+	}
+
+	return values
+}
+
+// redactedError is an error with the values of secret fields masked.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// redactError masks the values of secret fields in the message of err, e.g.
+// of unmarshal errors which quote the invalid value.
+func (c *loader[T]) redactError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	msg := err.Error()
+	for _, value := range c.secretValues() {
+		msg = strings.ReplaceAll(msg, value, redacted)
+	}
+
+	if msg == err.Error() {
+		return err
+	}
+
+	return &redactedError{msg: msg, err: err}
+}
+
+// redactSettings masks the values of secret fields in the settings of the
+// section.
+func (c *loader[T]) redactSettings(settings map[string]any, section string) {
+	prefix := strings.ToLower(section)
+	if prefix != "" {
+		prefix += keyDelimiter
+	}
+
+	for _, key := range c.secretKeys() {
+		path := strings.Split(strings.TrimPrefix(key, prefix), keyDelimiter)
+
+		nested := settings
+		for _, name := range path[:len(path)-1] {
+			if nested, _ = nested[name].(map[string]any); nested == nil {
+				break
+			}
+		}
+
+		if _, ok := nested[path[len(path)-1]]; ok {
+			nested[path[len(path)-1]] = redacted
+		}
+	}
+}
+
+// isSecretKey reports whether the key is one of the secret keys or nested in
+// one of them.
+func isSecretKey(key string, secrets []string) bool {
+	for _, secret := range secrets {
+		if key == secret || strings.HasPrefix(key, secret+keyDelimiter) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactValue masks the value, nil stays nil to show added and removed keys.
+func redactValue(value any) any {
+	if value == nil {
+		return nil
+	}
+
+	return redacted
+}
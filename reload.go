@@ -0,0 +1,258 @@
+package config
+
+import (
+	"crypto/sha256"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WithReloadOnSignal is an option to reload the configuration when the process
+// receives one of the given signals. Defaults to SIGHUP if no signal is given.
+func WithReloadOnSignal[T any](sig ...os.Signal) Option[T] {
+	return func(cl *loader[T]) {
+		if len(sig) == 0 {
+			sig = []os.Signal{syscall.SIGHUP}
+		}
+
+		cl.reloadSignals = sig
+	}
+}
+
+// WithPollingWatcher is an option to detect config file changes by polling the
+// file in the given interval instead of using fsnotify. This is useful for
+// network filesystems like NFS or SMB, where fsnotify events are unreliable.
+// A change is reloaded once the content is the same in two consecutive polls.
+func WithPollingWatcher[T any](interval time.Duration) Option[T] {
+	return func(cl *loader[T]) {
+		cl.pollInterval = interval
+	}
+}
+
+// WithKubernetesWatcher is an option to watch a config file mounted from a
+// Kubernetes ConfigMap. ConfigMap updates swap the "..data" symlink instead of
+// writing the file, the watcher follows the symlink and reloads exactly once
+// per update.
+func WithKubernetesWatcher[T any]() Option[T] {
+	return func(cl *loader[T]) {
+		cl.kubernetesWatcher = true
+	}
+}
+
+// Reload reads the config files and sources again and parses the config like
+// a reload of the watcher, the result is also reported to the change callbacks.
+// If the config is invalid, the current config is kept and the error returned.
+func (c *loader[T]) Reload() error {
+	return c.reload(triggerManual)
+}
+
+// watchSignals installs the signal handler and triggers a reload on receipt.
+func (c *loader[T]) watchSignals() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, c.reloadSignals...)
+
+	go func() {
+		defer signal.Stop(signals)
+
+		for {
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-signals:
+				c.logger.Info("Received signal, reloading config")
+				c.triggerReload(triggerSignal)
+			}
+		}
+	}()
+}
+
+// reread reads the config files again, applies the profile and merges the
+// sources and the dotenv files.
+func (c *loader[T]) reread() error {
+	if err := c.readConfig(); err != nil {
+		return err
+	}
+
+	if err := c.applyProfile(); err != nil {
+		return err
+	}
+
+	if err := c.mergeSources(); err != nil {
+		return err
+	}
+
+	return c.mergeDotEnv()
+}
+
+// readConfig reads the config files again, or restores the data of a reader.
+func (c *loader[T]) readConfig() error {
+	switch {
+	case len(c.configFiles) > 0 || c.configDir != "":
+		return c.readConfigFiles()
+	case c.viper.ConfigFileUsed() != "":
+		return c.readInConfig()
+	case c.readerConfig != nil:
+		return c.readConfigData(c.readerConfig)
+	case len(c.sources) > 0:
+		// Clear the config, the sources are merged on top
+		c.viper.SetConfigType("json")
+		c.resetOrigins()
+
+		return c.viper.ReadConfig(strings.NewReader("{}"))
+	}
+
+	return nil
+}
+
+// hasConfigFiles reports whether the config is read from files.
+func (c *loader[T]) hasConfigFiles() bool {
+	return len(c.configFiles) > 0 || c.configDir != "" || c.viper.ConfigFileUsed() != ""
+}
+
+// watchedFiles returns all config files which are monitored for changes.
+func (c *loader[T]) watchedFiles() []string {
+	configFiles := []string{c.viper.ConfigFileUsed()}
+	if len(c.configFiles) > 0 || c.configDir != "" {
+		configFiles, _ = c.sourceFiles()
+	}
+
+	if profileFile := c.profileFile(); profileFile != "" {
+		if _, err := os.Stat(profileFile); err == nil {
+			configFiles = append(configFiles, profileFile)
+		}
+	}
+
+	return configFiles
+}
+
+// pollConfigFiles hashes the config files in the poll interval and triggers a
+// reload once the content has changed and is the same in two consecutive
+// polls, so files which are still being written are not read.
+func (c *loader[T]) pollConfigFiles() {
+	lastSum, _ := c.configFilesSum()
+
+	var (
+		changedSum [sha256.Size]byte // content of the previous poll, if changed
+		changed    bool
+	)
+
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		sum, err := c.configFilesSum()
+		if err != nil {
+			c.logger.Error("Failed to poll config file", "error", err)
+
+			continue
+		}
+
+		switch {
+		case sum == lastSum:
+			changed = false
+		case !changed || sum != changedSum:
+			// the files may still be written, wait for the next poll
+			changed, changedSum = true, sum
+		default:
+			changed, lastSum = false, sum
+			c.triggerReload(triggerFile)
+		}
+	}
+}
+
+// configFilesSum returns the checksum of the content of all config files.
+func (c *loader[T]) configFilesSum() ([sha256.Size]byte, error) {
+	hash := sha256.New()
+
+	for _, configFile := range c.watchedFiles() {
+		content, err := os.ReadFile(configFile)
+		if err != nil {
+			return [sha256.Size]byte{}, err
+		}
+
+		hash.Write(content)
+	}
+
+	return [sha256.Size]byte(hash.Sum(nil)), nil
+}
+
+// watchConfigFiles watches the directories of the config files and triggers a
+// reload if a resolved config file changed or was written. Added or removed
+// files of a config directory also trigger a reload.
+func (c *loader[T]) watchConfigFiles() {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		c.logger.Error("Failed to create watcher", "error", err)
+
+		return
+	}
+	defer watcher.Close()
+
+	realConfigFiles := make(map[string]string)
+
+	for _, configFile := range c.watchedFiles() {
+		configFile = filepath.Clean(configFile)
+		realConfigFiles[configFile], _ = filepath.EvalSymlinks(configFile)
+
+		if err := watcher.Add(filepath.Dir(configFile)); err != nil {
+			c.logger.Error("Failed to watch config directory", "error", err)
+
+			return
+		}
+	}
+
+	if c.configDir != "" {
+		if err := watcher.Add(c.configDir); err != nil {
+			c.logger.Error("Failed to watch config directory", "error", err)
+
+			return
+		}
+	}
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+
+			changed := c.configDir != "" && filepath.Dir(event.Name) == c.configDir &&
+				isConfigExt(configExt(event.Name))
+
+			for configFile, realConfigFile := range realConfigFiles {
+				currentConfigFile, _ := filepath.EvalSymlinks(configFile)
+
+				switch {
+				case currentConfigFile != "" && currentConfigFile != realConfigFile:
+					realConfigFiles[configFile] = currentConfigFile
+					changed = true
+				case filepath.Clean(event.Name) == configFile && event.Has(fsnotify.Write|fsnotify.Create):
+					changed = true
+				}
+			}
+
+			if changed {
+				c.triggerReload(triggerFile)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+
+			c.logger.Error("Config watcher error", "error", err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BackoffFunc returns the delay before the retry after the given failed
+// attempt, starting at 1.
+type BackoffFunc func(attempt int) time.Duration
+
+// ExponentialBackoff returns a backoff doubling the delay from base up to
+// maxDelay, with full jitter: the delay is random between zero and the
+// exponential value.
+func ExponentialBackoff(base, maxDelay time.Duration) BackoffFunc {
+	return func(attempt int) time.Duration {
+		delay := maxDelay
+		if attempt < 32 {
+			if d := base << (attempt - 1); d > 0 && d < maxDelay {
+				delay = d
+			}
+		}
+
+		return rand.N(delay + 1)
+	}
+}
+
+// WithRetryPolicy is an option to retry failed reads of the sources, on load
+// and on reloads, up to maxAttempts times with the backoff between the
+// attempts. Only transient errors are retried: network errors, timeouts and
+// HTTP statuses 429 and 5xx. A nil backoff uses
+// ExponentialBackoff(100*time.Millisecond, 5*time.Second).
+func WithRetryPolicy[T any](maxAttempts int, backoff BackoffFunc) Option[T] {
+	return func(cl *loader[T]) {
+		if backoff == nil {
+			backoff = ExponentialBackoff(100*time.Millisecond, 5*time.Second)
+		}
+
+		cl.retryAttempts = maxAttempts
+		cl.retryBackoff = backoff
+	}
+}
+
+// isRetryable reports whether a failed read may succeed if it is retried, like
+// for transport errors, rate limits and server errors.
+func isRetryable(err error) bool {
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code == http.StatusTooManyRequests || statusErr.code >= http.StatusInternalServerError
+	}
+
+	return isTransportError(err) || status.Code(err) == codes.ResourceExhausted
+}
+
+// readWithRetry reads the source, retrying failed reads by the retry policy.
+func (c *loader[T]) readWithRetry(s Source) ([]byte, string, error) {
+	data, configType, err := s.Read()
+
+	for attempt := 1; err != nil && isRetryable(err) && attempt < c.retryAttempts; attempt++ {
+		time.Sleep(c.retryBackoff(attempt))
+
+		data, configType, err = s.Read()
+	}
+
+	return data, configType, err
+}
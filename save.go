@@ -0,0 +1,241 @@
+package config
+
+import (
+	"encoding"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var errNotLoaded = errors.New("config not loaded")
+
+// Save writes the current config to the file in the format, e.g. "yaml" or
+// "json", derived from the extension of the file if empty. The typed config is
+// written, not the raw values of the sources, so values changed at runtime are
+// persisted. Resolved values are written as the references they were
+// resolved from, like secret references, "file://" references and ${VAR}
+// placeholders, so secrets are not written in plain text. []byte values are
+// written base64 encoded with the "base64:" prefix. The file is replaced
+// atomically and keeps its permissions, new files are created with 0600.
+func (c *loader[T]) Save(path, format string) error {
+	config := c.config.Load()
+	if config == nil {
+		return errNotLoaded
+	}
+
+	if format == "" {
+		format = strings.TrimPrefix(configExt(path), ".")
+	}
+
+	settings, _ := structSettings(reflect.ValueOf(config)).(map[string]any)
+
+	c.reloadMu.Lock()
+	raw, _ := c.settings()
+	c.reloadMu.Unlock()
+
+	c.keepReferences(settings, raw)
+
+	data, err := encodeConfig(settings, format)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	return writeFileAtomic(path, data)
+}
+
+// keepReferences replaces the resolved values of the settings by the raw
+// values, which are references.
+func (c *loader[T]) keepReferences(settings, raw map[string]any) {
+	rawByKey := make(map[string]any, len(raw))
+	for key, value := range raw {
+		rawByKey[strings.ToLower(key)] = value
+	}
+
+	for key, value := range settings {
+		rawValue := rawByKey[strings.ToLower(key)]
+
+		if nested, ok := value.(map[string]any); ok {
+			if rawNested, ok := rawValue.(map[string]any); ok {
+				c.keepReferences(nested, rawNested)
+			}
+
+			continue
+		}
+
+		if reference, ok := rawValue.(string); ok && c.isReference(reference) {
+			settings[key] = reference
+		}
+	}
+}
+
+// isReference reports whether the value is resolved when the config is
+// parsed, e.g. "vault:secret/data/myapp#password".
+func (c *loader[T]) isReference(value string) bool {
+	scheme, _, ok := strings.Cut(value, ":")
+	if _, registered := c.secretResolvers[scheme]; ok && registered {
+		return true
+	}
+
+	return (c.fileReferences && strings.HasPrefix(value, filePrefix)) ||
+		(c.envInterpolation && strings.Contains(value, "${"))
+}
+
+// writeFileAtomic writes the data to a temporary file in the directory of the
+// file and renames it to the file, so readers never see a partial file.
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0o600)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// structSettings returns the value as settings like they are decoded from a
+// config file: structs are maps by config key and values decoded from strings
+// like durations, URLs and byte sizes are strings, []byte values are base64
+// encoded. Nil values return nil.
+func structSettings(v reflect.Value) any {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+
+		v = v.Elem()
+	}
+
+	if s, ok := textValue(v); ok {
+		return s
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		settings := map[string]any{}
+		addStructSettings(settings, v)
+
+		return settings
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+
+		values := make([]any, v.Len())
+		for i := range v.Len() {
+			values[i] = structSettings(v.Index(i))
+		}
+
+		return values
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+
+		settings := make(map[string]any, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			settings[fmt.Sprint(iter.Key().Interface())] = structSettings(iter.Value())
+		}
+
+		return settings
+	default:
+		return v.Interface()
+	}
+}
+
+// addStructSettings adds the exported fields of the struct to the settings,
+// keyed like walkFields, squashed structs are added in place.
+func addStructSettings(settings map[string]any, v reflect.Value) {
+	t := v.Type()
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if name == "-" || opts == "remain" {
+			continue
+		}
+
+		if opts == "squash" || (field.Anonymous && name == "") {
+			if embedded, ok := structSettings(v.Field(i)).(map[string]any); ok {
+				for key, value := range embedded {
+					settings[key] = value
+				}
+			}
+
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if value := structSettings(v.Field(i)); value != nil {
+			settings[name] = value
+		}
+	}
+}
+
+// textValue returns the string of values which are decoded from strings, like
+// durations, URLs and types implementing encoding.TextMarshaler.
+func textValue(v reflect.Value) (string, bool) {
+	switch v.Type() {
+	case durationType:
+		return time.Duration(v.Int()).String(), true
+	case bytesType:
+		return base64Prefix + base64.StdEncoding.EncodeToString(v.Bytes()), true
+	}
+
+	// methods of pointer receivers like of url.URL need an addressable value
+	if !v.CanAddr() {
+		addressable := reflect.New(v.Type()).Elem()
+		addressable.Set(v)
+		v = addressable
+	}
+
+	switch value := v.Addr().Interface().(type) {
+	case encoding.TextMarshaler:
+		text, err := value.MarshalText()
+
+		return string(text), err == nil
+	case fmt.Stringer:
+		if v.Type() == urlType || v.Type() == ipNetType {
+			return value.String(), true
+		}
+	}
+
+	return "", false
+}
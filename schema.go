@@ -0,0 +1,119 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// enumTag is the struct tag of the allowed values of a field, comma separated.
+const enumTag = "enum"
+
+// schemaDraft is the JSON Schema version of Schema.
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema returns a JSON Schema of the config of T to validate config files in
+// editors and CI. It contains the types, the doc tags as descriptions, the
+// default tags, the enum tags as allowed values and the required tags.
+func Schema[T any]() ([]byte, error) {
+	schema := objectSchema(describeFields(reflect.TypeFor[T](), ""), map[reflect.Type]bool{})
+	schema["$schema"] = schemaDraft
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// objectSchema returns the schema of a section with the fields, visited
+// prevents endless recursion of structs in lists and maps of themselves.
+func objectSchema(fields []fieldInfo, visited map[reflect.Type]bool) map[string]any {
+	properties := make(map[string]any)
+
+	var required []string
+
+	for _, f := range fields {
+		properties[f.name] = fieldSchema(f, visited)
+
+		if f.required {
+			required = append(required, f.name)
+		}
+	}
+
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+// fieldSchema returns the schema of the field.
+func fieldSchema(f fieldInfo, visited map[reflect.Type]bool) map[string]any {
+	var schema map[string]any
+	if f.section {
+		schema = objectSchema(f.fields, visited)
+	} else {
+		schema = typeSchema(f.typ, visited)
+	}
+
+	if doc := f.doc(); doc != "" {
+		schema["description"] = doc
+	}
+
+	if value, ok := f.defaultValue(); ok {
+		schema["default"] = typedValue(f.typ, value)
+	}
+
+	if enum, ok := f.field.Tag.Lookup(enumTag); ok {
+		var values []any
+		for _, value := range strings.Split(enum, ",") {
+			values = append(values, typedValue(f.typ, strings.TrimSpace(value)))
+		}
+
+		schema["enum"] = values
+	}
+
+	return schema
+}
+
+// typeSchema returns the schema of values of type t.
+func typeSchema(t reflect.Type, visited map[reflect.Type]bool) map[string]any {
+	t = indirectType(t)
+
+	switch {
+	case t == durationType:
+		return map[string]any{"type": "string", "pattern": `^([-+]?([0-9]*(\.[0-9]*)?[a-zµ]+)+|0)$`}
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t == urlType:
+		return map[string]any{"type": "string", "format": "uri"}
+	case isTextType(t):
+		return map[string]any{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem(), visited)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem(), visited)}
+	case reflect.Struct:
+		if visited[t] {
+			return map[string]any{"type": "object"}
+		}
+
+		visited[t] = true
+		defer delete(visited, t)
+
+		return objectSchema(describeFields(t, ""), visited)
+	default:
+		return map[string]any{}
+	}
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"schneider.vip/config/internal/aws"
+	"schneider.vip/config/internal/gcp"
+)
+
+var errSecretKeyNotFound = errors.New("key not found in secret")
+
+// SecretResolver resolves the secret references of config values, e.g. the
+// reference "secret/data/myapp#password" of "vault:secret/data/myapp#password".
+type SecretResolver interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// SecretResolverFunc is a function implementing SecretResolver.
+type SecretResolverFunc func(ctx context.Context, ref string) (string, error)
+
+// Resolve calls f.
+func (f SecretResolverFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+// WithSecretResolver is an option to resolve values like "scheme:ref" by the
+// resolver, e.g. "vault" for "vault:secret/data/myapp#password". References
+// are resolved on every Parse, so reloads pick up rotated secrets. A
+// resolution is canceled after the timeout of the opts, see WithTimeout.
+func WithSecretResolver[T any](scheme string, resolver SecretResolver, opts ...RemoteOption) Option[T] {
+	return func(cl *loader[T]) {
+		if cl.secretResolvers == nil {
+			cl.secretResolvers = make(map[string]secretResolver)
+		}
+
+		cl.secretResolvers[scheme] = secretResolver{resolver: resolver, opts: newRemoteOptions("", opts)}
+	}
+}
+
+// secretResolver is a registered resolver with the options of its reads.
+type secretResolver struct {
+	resolver SecretResolver
+	opts     remoteOptions
+}
+
+// secretHook resolves the secret references of string values.
+func secretHook(resolvers map[string]secretResolver) func(reflect.Type, reflect.Type, any) (any, error) {
+	return func(_ reflect.Type, _ reflect.Type, data any) (any, error) {
+		value, ok := data.(string)
+		if !ok {
+			return data, nil
+		}
+
+		scheme, ref, ok := strings.Cut(value, ":")
+		r, registered := resolvers[scheme]
+
+		if !ok || !registered {
+			return data, nil
+		}
+
+		ctx, cancel := r.opts.readContext()
+		defer cancel()
+
+		secret, err := r.resolver.Resolve(ctx, ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s secret %s: %w", scheme, ref, err)
+		}
+
+		return secret, nil
+	}
+}
+
+// VaultResolver returns a resolver of KV v2 secrets of HashiCorp Vault, the
+// reference is the path and the key of the secret, e.g.
+// "secret/data/myapp#password".
+func VaultResolver(address string, auth VaultAuth, opts ...RemoteOption) SecretResolver {
+	s := &vaultSource{
+		address: strings.TrimSuffix(address, "/"),
+		auth:    auth,
+		opts:    newRemoteOptions("", opts),
+	}
+
+	return SecretResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		path, key, _ := strings.Cut(ref, "#")
+
+		secret, err := s.readSecret(ctx, strings.TrimPrefix(path, "/"))
+		if err != nil {
+			return "", err
+		}
+
+		return secretKey(secret, key)
+	})
+}
+
+// AWSSecretsManagerResolver returns a resolver of secrets of AWS Secrets
+// Manager, the reference is the secret ID and optionally the key of a JSON
+// secret, e.g. "prod/myapp#password".
+func AWSSecretsManagerResolver(opts ...RemoteOption) SecretResolver {
+	o := newRemoteOptions("", opts)
+	client, clientErr := aws.NewClient(o.httpClient, o.region, o.endpoint)
+
+	return SecretResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		if clientErr != nil {
+			return "", clientErr
+		}
+
+		secretID, key, _ := strings.Cut(ref, "#")
+
+		secret, _, err := getSecretValue(ctx, client, secretID)
+		if err != nil {
+			return "", err
+		}
+
+		return jsonSecretKey(secret, key)
+	})
+}
+
+// GCPSecretManagerResolver returns a resolver of secrets of GCP Secret
+// Manager, the reference is the secret version like in WithGCPSecretManager and
+// optionally the key of a JSON secret, e.g. "db-credentials#password".
+func GCPSecretManagerResolver(opts ...RemoteOption) SecretResolver {
+	o := newRemoteOptions("", opts)
+	if o.endpoint == "" {
+		o.endpoint = gcpSecretManagerEndpoint
+	}
+
+	s := &gcpSecretManagerSource{
+		opts:   o,
+		tokens: gcp.NewTokenProvider(o.httpClient),
+	}
+
+	return SecretResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		secretName, key, _ := strings.Cut(ref, "#")
+
+		secret, err := s.accessSecret(ctx, secretName)
+		if err != nil {
+			return "", err
+		}
+
+		return jsonSecretKey([]byte(secret), key)
+	})
+}
+
+// jsonSecretKey returns the secret, or the value of the key if the secret is a
+// JSON object.
+func jsonSecretKey(secret []byte, key string) (string, error) {
+	if key == "" {
+		return string(secret), nil
+	}
+
+	var settings map[string]any
+	if err := json.Unmarshal(secret, &settings); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object: %w", err)
+	}
+
+	return secretKey(settings, key)
+}
+
+// secretKey returns the value of the key of the secret, the whole secret as
+// JSON if the key is empty.
+func secretKey(secret map[string]any, key string) (string, error) {
+	if key == "" {
+		data, err := json.Marshal(secret)
+
+		return string(data), err
+	}
+
+	value, ok := secret[key]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", errSecretKeyNotFound, key)
+	}
+
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+
+	return fmt.Sprint(value), nil
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"schneider.vip/config/internal/aws"
+)
+
+// rotationDelay is the time waited after a scheduled rotation before the
+// secret is read, to give the rotation function time to finish.
+const rotationDelay = time.Minute
+
+// WithAWSSecretsManager is an option to load a JSON secret of AWS Secrets
+// Manager. The keys of the JSON object map to config keys, use WithSection to
+// merge the secret into a section of the config. StartWatcher reads the secret
+// after its next scheduled rotation and at least in the poll interval, so
+// rotated credentials are updated without a restart.
+func WithAWSSecretsManager[T any](secretID string, opts ...RemoteOption) Option[T] {
+	return func(cl *loader[T]) {
+		o := newRemoteOptions("", opts)
+
+		client, err := aws.NewClient(o.httpClient, o.region, o.endpoint)
+		if err != nil {
+			cl.addSource(errSource{err: err})
+
+			return
+		}
+
+		cl.addSource(&secretsManagerSource{
+			secretID: secretID,
+			opts:     o,
+			client:   client,
+		})
+	}
+}
+
+// secretsManagerSource reads a secret of AWS Secrets Manager.
+type secretsManagerSource struct {
+	secretID string
+	opts     remoteOptions
+	client   *aws.Client
+}
+
+// Read returns the secret as JSON.
+func (s *secretsManagerSource) Read() ([]byte, string, error) {
+	ctx, cancel := s.opts.readContext()
+	defer cancel()
+
+	data, _, err := s.getSecretValue(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, "json", nil
+}
+
+// Watch reads the secret after each scheduled rotation and in the poll
+// interval and sends a change if a new version of the secret is current.
+func (s *secretsManagerSource) Watch(ctx context.Context) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+
+	go func() {
+		defer close(changes)
+
+		_, versionID, _ := s.getSecretValue(ctx)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(s.nextCheck(ctx)):
+			}
+
+			_, newVersionID, err := s.getSecretValue(ctx)
+			if err != nil || newVersionID != versionID {
+				versionID = newVersionID
+				notify(changes)
+			}
+		}
+	}()
+
+	return changes
+}
+
+// nextCheck returns the duration until the secret is read again, which is the
+// poll interval or the time until shortly after the next scheduled rotation.
+func (s *secretsManagerSource) nextCheck(ctx context.Context) time.Duration {
+	var output struct {
+		NextRotationDate float64 `json:"NextRotationDate"`
+	}
+
+	err := s.client.CallJSON(ctx, "secretsmanager", "secretsmanager.DescribeSecret",
+		map[string]any{"SecretId": s.secretID}, &output)
+	if err != nil || output.NextRotationDate == 0 {
+		return s.opts.pollInterval
+	}
+
+	nextRotation := time.Unix(int64(output.NextRotationDate), 0).Add(rotationDelay)
+	if untilRotation := time.Until(nextRotation); untilRotation > 0 && untilRotation < s.opts.pollInterval {
+		return untilRotation
+	}
+
+	return s.opts.pollInterval
+}
+
+// getSecretValue returns the current secret as JSON and its version.
+func (s *secretsManagerSource) getSecretValue(ctx context.Context) ([]byte, string, error) {
+	secret, versionID, err := getSecretValue(ctx, s.client, s.secretID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var settings map[string]any
+	if err := json.Unmarshal(secret, &settings); err != nil {
+		return nil, "", fmt.Errorf("secret %s is not a JSON object: %w", s.secretID, err)
+	}
+
+	data, err := json.Marshal(nestSection(s.opts.section, settings))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, versionID, nil
+}
+
+// getSecretValue returns the current value of the secret and its version.
+func getSecretValue(ctx context.Context, client *aws.Client, secretID string) ([]byte, string, error) {
+	var output struct {
+		SecretString string `json:"SecretString"`
+		SecretBinary []byte `json:"SecretBinary"`
+		VersionID    string `json:"VersionId"`
+	}
+
+	err := client.CallJSON(ctx, "secretsmanager", "secretsmanager.GetSecretValue",
+		map[string]any{"SecretId": secretID}, &output)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if output.SecretString != "" {
+		return []byte(output.SecretString), output.VersionID, nil
+	}
+
+	return output.SecretBinary, output.VersionID, nil
+}
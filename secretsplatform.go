@@ -0,0 +1,216 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	dopplerEndpoint   = "https://api.doppler.com"
+	infisicalEndpoint = "https://app.infisical.com"
+)
+
+// WithDoppler is an option to load the secrets of a Doppler config. Secret
+// names are mapped like environment variables, "DATABASECONFIG_HOST" sets
+// "databaseConfig.host". Project and config may be empty for service tokens,
+// which are scoped to a config. StartWatcher polls the secrets in the poll
+// interval.
+func WithDoppler[T any](token, project, config string, opts ...RemoteOption) Option[T] {
+	return func(cl *loader[T]) {
+		o := newRemoteOptions("", opts)
+		if o.endpoint == "" {
+			o.endpoint = dopplerEndpoint
+		}
+
+		query := url.Values{"format": {"json"}}
+		if project != "" {
+			query.Set("project", project)
+		}
+
+		if config != "" {
+			query.Set("config", config)
+		}
+
+		cl.addSource(&secretsPlatformSource{
+			name: o.endpoint + "?" + query.Encode(),
+			opts: o,
+			fetch: func(ctx context.Context) (map[string]string, error) {
+				req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+					strings.TrimSuffix(o.endpoint, "/")+"/v3/configs/config/secrets/download?"+query.Encode(), nil)
+				if err != nil {
+					return nil, err
+				}
+
+				req.Header.Set("Authorization", "Bearer "+token)
+
+				var secrets map[string]string
+				if err := getJSON(o.httpClient, req, &secrets); err != nil {
+					return nil, fmt.Errorf("failed to read doppler secrets: %w", err)
+				}
+
+				return secrets, nil
+			},
+		})
+	}
+}
+
+// WithInfisical is an option to load the secrets at the path, e.g. "/", of an
+// Infisical project environment like "prod". Secret names are mapped like
+// environment variables, "DATABASECONFIG_HOST" sets "databaseConfig.host".
+// WithBearerToken sets an access token, WithBasicAuth logs in with the client
+// id and secret of a machine identity (universal auth). WithEndpoint sets the
+// URL of a self-hosted instance. StartWatcher polls the secrets in the poll
+// interval.
+func WithInfisical[T any](projectID, environment, secretPath string, opts ...RemoteOption) Option[T] {
+	return func(cl *loader[T]) {
+		o := newRemoteOptions("", opts)
+		if o.endpoint == "" {
+			o.endpoint = infisicalEndpoint
+		}
+
+		o.endpoint = strings.TrimSuffix(o.endpoint, "/")
+		query := url.Values{
+			"workspaceId": {projectID},
+			"environment": {environment},
+			"secretPath":  {secretPath},
+		}
+		auth := &infisicalAuth{opts: o}
+
+		cl.addSource(&secretsPlatformSource{
+			name: o.endpoint + "?" + query.Encode(),
+			opts: o,
+			fetch: func(ctx context.Context) (map[string]string, error) {
+				token, err := auth.token(ctx)
+				if err != nil {
+					return nil, err
+				}
+
+				req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.endpoint+"/api/v3/secrets/raw?"+query.Encode(), nil)
+				if err != nil {
+					return nil, err
+				}
+
+				req.Header.Set("Authorization", "Bearer "+token)
+
+				var response struct {
+					Secrets []struct {
+						Key   string `json:"secretKey"`
+						Value string `json:"secretValue"`
+					} `json:"secrets"`
+				}
+
+				if err := getJSON(o.httpClient, req, &response); err != nil {
+					return nil, fmt.Errorf("failed to read infisical secrets: %w", err)
+				}
+
+				secrets := make(map[string]string, len(response.Secrets))
+				for _, secret := range response.Secrets {
+					secrets[secret.Key] = secret.Value
+				}
+
+				return secrets, nil
+			},
+		})
+	}
+}
+
+// infisicalAuth returns the access token of an Infisical machine identity.
+type infisicalAuth struct {
+	opts    remoteOptions
+	mu      sync.Mutex
+	access  string
+	expires time.Time
+}
+
+// token returns the configured token or a cached or new universal auth token.
+func (a *infisicalAuth) token(ctx context.Context) (string, error) {
+	if a.opts.token != "" {
+		return a.opts.token, nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.access != "" && time.Until(a.expires) > time.Minute {
+		return a.access, nil
+	}
+
+	body, err := postJSON(ctx, a.opts.httpClient, a.opts.endpoint+"/api/v1/auth/universal-auth/login", "", map[string]string{
+		"clientId":     a.opts.username,
+		"clientSecret": a.opts.password,
+	})
+	if err != nil {
+		return "", fmt.Errorf("infisical login failed: %w", err)
+	}
+	defer body.Close()
+
+	var response struct {
+		AccessToken string `json:"accessToken"`
+		ExpiresIn   int    `json:"expiresIn"`
+	}
+
+	if err := json.NewDecoder(body).Decode(&response); err != nil {
+		return "", fmt.Errorf("infisical login failed: %w", err)
+	}
+
+	a.access = response.AccessToken
+	a.expires = time.Now().Add(time.Duration(response.ExpiresIn) * time.Second)
+
+	return a.access, nil
+}
+
+// secretsPlatformSource reads the secrets of a secrets platform like Doppler.
+type secretsPlatformSource struct {
+	name  string // address of the secrets, e.g. the endpoint and the project
+	opts  remoteOptions
+	fetch func(ctx context.Context) (map[string]string, error)
+}
+
+// Read returns the secrets as JSON, names are split into nested keys at "_".
+func (s *secretsPlatformSource) Read() ([]byte, string, error) {
+	ctx, cancel := s.opts.readContext()
+	defer cancel()
+
+	secrets, err := s.fetch(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	settings := make(map[string]any, len(secrets))
+	for name, value := range secrets {
+		setPath(settings, strings.Split(strings.ToLower(name), keyDelimiter), value)
+	}
+
+	data, err := json.Marshal(nestSection(s.opts.section, settings))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, "json", nil
+}
+
+// Watch polls the secrets for changes.
+func (s *secretsPlatformSource) Watch(ctx context.Context) <-chan struct{} {
+	return pollChanges(ctx, s.opts.pollInterval, func() ([]byte, error) {
+		data, _, err := s.Read()
+
+		return data, err
+	})
+}
+
+// getJSON sends the request and decodes the JSON body of a successful response.
+func getJSON(client *http.Client, req *http.Request, response any) error {
+	resp, err := doRequest(client, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return json.NewDecoder(resp.Body).Decode(response)
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	errUnknownField = errors.New("unknown config field")
+	errFieldType    = errors.New("mismatched config field type")
+)
+
+// Set sets the value of the key at runtime, e.g. "databaseConfig.host" or
+// "databaseconfig_host", and parses the config again. The value overrides all
+// sources, also on later reloads, and is published to Load and the change
+// callbacks. A nil value removes the value set before. If the config is
+// invalid with the value, the value is discarded and the current config is
+// kept.
+func (c *loader[T]) Set(keyPath string, value any) error {
+	key := aliasKey(keyPath)
+	if _, ok := fieldByKey[T](key); !ok {
+		return fmt.Errorf("%w: %q", errUnknownField, keyPath)
+	}
+
+	if c.subSection != "" {
+		key = strings.ToLower(c.subSection) + keyDelimiter + key
+	}
+
+	// typed values like durations are set like they are decoded from files
+	if value != nil {
+		value = structSettings(reflect.ValueOf(value))
+	}
+
+	return c.override(triggerSet, map[string]any{key: value})
+}
+
+// SetField sets the value of the key at runtime like Set, the type of the
+// value must match the type of the field, e.g. time.Duration for a duration.
+func SetField[T, V any](loader Manager[T], keyPath string, value V) error {
+	field, ok := fieldByKey[T](aliasKey(keyPath))
+	if !ok {
+		return fmt.Errorf("%w: %q", errUnknownField, keyPath)
+	}
+
+	if typ := reflect.TypeFor[V](); indirectType(typ) != indirectType(field.Type) {
+		return fmt.Errorf("%w: %q is %s, not %s", errFieldType, keyPath, field.Type, typ)
+	}
+
+	return loader.Set(keyPath, value)
+}
+
+// fieldByKey returns the field of T of the config key.
+func fieldByKey[T any](key string) (reflect.StructField, bool) {
+	var found reflect.StructField
+
+	errFound := errors.New("found")
+
+	err := walkFields(reflect.TypeFor[T](), "", func(fieldKey string, field reflect.StructField) error {
+		if fieldKey != key {
+			return nil
+		}
+
+		found = field
+
+		return errFound
+	})
+
+	return found, errors.Is(err, errFound)
+}
+
+// setOverride sets the value of the key over all sources, nil removes it.
+func (c *loader[T]) setOverride(key string, value any) {
+	if c.overrides == nil {
+		c.overrides = make(map[string]any)
+	}
+
+	if value == nil {
+		delete(c.overrides, key)
+	} else {
+		c.overrides[key] = value
+	}
+
+	// viper has no unset, nil overrides are skipped for the other sources
+	c.viper.Set(key, value)
+}
+
+// override sets the values by key over all sources and parses the config
+// again, the values are discarded if the config is invalid with them.
+func (c *loader[T]) override(trigger string, values map[string]any) error {
+	ctx, span := c.startSpan(context.Background(), "config.Override", attribute.String("config.trigger", trigger))
+
+	c.reloadMu.Lock()
+
+	previous := c.config.Load()
+	previousSettings, _ := c.settings()
+	previousValues := make(map[string]any, len(values))
+
+	for key, value := range values {
+		previousValues[key] = c.overrides[key]
+		c.setOverride(key, value)
+	}
+
+	if err := c.parseContext(ctx); err != nil {
+		for key, value := range previousValues {
+			c.setOverride(key, value)
+		}
+
+		c.audit(trigger, err, nil, nil)
+		c.reloadMu.Unlock()
+		endSpan(span, err)
+
+		return err
+	}
+
+	diff := c.publish(span, trigger, previousSettings)
+	current := c.config.Load()
+	c.reloadMu.Unlock()
+
+	c.logger.Info("Config values set", "trigger", trigger, "changes", len(diff))
+	endSpan(span, nil)
+	c.notify(previous, current, diff, nil)
+
+	return nil
+}
@@ -0,0 +1,223 @@
+package config
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"golang.org/x/crypto/blake2b"
+)
+
+var (
+	errInvalidSignature = errors.New("invalid signature")
+	errInvalidPublicKey = errors.New("invalid public key")
+)
+
+// SignatureVerifier verifies the detached signature of a config file.
+type SignatureVerifier interface {
+	// Verify returns an error unless signature is a valid signature of data.
+	Verify(data, signature []byte) error
+}
+
+// SignatureVerifierFunc is a function implementing SignatureVerifier.
+type SignatureVerifierFunc func(data, signature []byte) error
+
+// Verify calls f.
+func (f SignatureVerifierFunc) Verify(data, signature []byte) error {
+	return f(data, signature)
+}
+
+// WithSignatureVerification is an option to verify the detached signature of
+// every config file before it is loaded or reloaded, e.g. config.yml.sig for
+// config.yml or config.yml.minisig of minisign. New fails like for invalid
+// configs and reloads keep the current config if a signature is missing or
+// invalid.
+func WithSignatureVerification[T any](verifier SignatureVerifier) Option[T] {
+	return func(cl *loader[T]) {
+		cl.signatureVerifier = verifier
+
+		// files given by earlier options are read again verified
+		if cl.hasConfigFiles() {
+			if err := cl.readConfig(); err != nil {
+				cl.logger.Error("Failed to read config from file", "error", err)
+			}
+		}
+	}
+}
+
+// verifySignature verifies the signature of the data of the config file.
+func (c *loader[T]) verifySignature(path string, data []byte) error {
+	if c.signatureVerifier == nil {
+		return nil
+	}
+
+	signatureFile := path + ".sig"
+	if v, ok := c.signatureVerifier.(interface{ signatureExt() string }); ok {
+		signatureFile = path + v.signatureExt()
+	}
+
+	// a missing signature is invalid, the error must not match fs.ErrNotExist
+	// like a missing config file
+	signature, err := os.ReadFile(signatureFile)
+	if err != nil {
+		return fmt.Errorf("%w: failed to read signature of %s: %v", errInvalidSignature, path, err)
+	}
+
+	if err := c.signatureVerifier.Verify(data, signature); err != nil {
+		return fmt.Errorf("failed to verify signature of %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// minisignVerifier verifies signatures of minisign.
+type minisignVerifier struct {
+	keyID     []byte
+	publicKey ed25519.PublicKey
+}
+
+// MinisignVerifier returns a verifier of minisign signatures, the signature
+// file is config.yml.minisig for config.yml. The public key is the base64
+// encoded key or the content of the public key file of minisign -G.
+func MinisignVerifier(publicKey string) (SignatureVerifier, error) {
+	lines := strings.Split(strings.TrimSpace(publicKey), "\n")
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[len(lines)-1]))
+	if err != nil || len(key) != 2+8+ed25519.PublicKeySize || string(key[:2]) != "Ed" {
+		return nil, fmt.Errorf("%w: not a minisign public key", errInvalidPublicKey)
+	}
+
+	return &minisignVerifier{keyID: key[2:10], publicKey: key[10:]}, nil
+}
+
+// Verify verifies the signature and the trusted comment of the signature
+// file, pre-hashed signatures are supported.
+func (v *minisignVerifier) Verify(data, signature []byte) error {
+	lines := strings.Split(strings.TrimSpace(string(signature)), "\n")
+	if len(lines) < 4 {
+		return fmt.Errorf("%w: not a minisign signature", errInvalidSignature)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(sig) != 2+8+ed25519.SignatureSize {
+		return fmt.Errorf("%w: not a minisign signature", errInvalidSignature)
+	}
+
+	if !bytes.Equal(sig[2:10], v.keyID) {
+		return fmt.Errorf("%w: signed by key %X", errInvalidSignature, sig[2:10])
+	}
+
+	message := data
+
+	switch string(sig[:2]) {
+	case "Ed":
+	case "ED":
+		hash := blake2b.Sum512(data)
+		message = hash[:]
+	default:
+		return fmt.Errorf("%w: unsupported algorithm %q", errInvalidSignature, sig[:2])
+	}
+
+	if !ed25519.Verify(v.publicKey, message, sig[10:]) {
+		return errInvalidSignature
+	}
+
+	// the global signature signs the signature and the trusted comment
+	comment, ok := strings.CutPrefix(strings.TrimRight(lines[2], "\r"), "trusted comment: ")
+	globalMessage := append(slices.Clip(sig[10:]), comment...)
+	globalSig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+
+	if !ok || err != nil || !ed25519.Verify(v.publicKey, globalMessage, globalSig) {
+		return fmt.Errorf("%w: invalid trusted comment", errInvalidSignature)
+	}
+
+	return nil
+}
+
+// signatureExt returns the extension of minisign signature files.
+func (v *minisignVerifier) signatureExt() string {
+	return ".minisig"
+}
+
+// CosignVerifier returns a verifier of signatures of cosign sign-blob with a
+// key pair, the signature file is config.yml.sig for config.yml. The public key
+// is PEM encoded like cosign.pub, ECDSA, Ed25519 and RSA keys are supported.
+func CosignVerifier(publicKey []byte) (SignatureVerifier, error) {
+	block, _ := pem.Decode(publicKey)
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM data", errInvalidPublicKey)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidPublicKey, err)
+	}
+
+	return SignatureVerifierFunc(func(data, signature []byte) error {
+		// cosign writes base64 encoded signatures
+		if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature))); err == nil {
+			signature = decoded
+		}
+
+		digest := sha256.Sum256(data)
+
+		var valid bool
+
+		switch key := key.(type) {
+		case *ecdsa.PublicKey:
+			valid = ecdsa.VerifyASN1(key, digest[:], signature)
+		case ed25519.PublicKey:
+			valid = ed25519.Verify(key, data, signature)
+		case *rsa.PublicKey:
+			valid = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+		default:
+			return fmt.Errorf("%w: unsupported key type %T", errInvalidPublicKey, key)
+		}
+
+		if !valid {
+			return errInvalidSignature
+		}
+
+		return nil
+	}), nil
+}
+
+// PGPVerifier returns a verifier of detached OpenPGP signatures like of gpg
+// --detach-sign, binary or armored, the signature file is config.yml.sig for
+// config.yml. The keyring contains the public keys of the signers, binary or
+// armored like of gpg --export --armor.
+func PGPVerifier(keyring []byte) (SignatureVerifier, error) {
+	keys, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(keyring))
+	if err != nil {
+		keys, err = openpgp.ReadKeyRing(bytes.NewReader(keyring))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidPublicKey, err)
+	}
+
+	return SignatureVerifierFunc(func(data, signature []byte) error {
+		check := openpgp.CheckDetachedSignature
+		if bytes.HasPrefix(bytes.TrimSpace(signature), []byte("-----BEGIN PGP SIGNATURE-----")) {
+			check = openpgp.CheckArmoredDetachedSignature
+		}
+
+		if _, err := check(keys, bytes.NewReader(data), bytes.NewReader(signature), nil); err != nil {
+			return fmt.Errorf("%w: %w", errInvalidSignature, err)
+		}
+
+		return nil
+	}), nil
+}
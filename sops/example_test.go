@@ -0,0 +1,35 @@
+package sops_test
+
+import (
+	"fmt"
+	"os"
+
+	"schneider.vip/config"
+	"schneider.vip/config/sops"
+)
+
+// GlobalConfig is a global config with a database section.
+type GlobalConfig struct {
+	DatabaseConfig struct {
+		Host string `mapstructure:"host"`
+		Port int    `mapstructure:"port"`
+	} `mapstructure:"databaseConfig"`
+}
+
+// ExampleWithSOPS demonstrates how to load a SOPS encrypted config file.
+func ExampleWithSOPS() {
+	// the age key of the test fixture, usually provided by the deployment
+	_ = os.Setenv("SOPS_AGE_KEY_FILE", "internal/sops-age-key.txt")
+
+	loader := config.New[GlobalConfig](
+		config.WithConfigFile[GlobalConfig]("internal/config.sops.yml"),
+		sops.WithSOPS[GlobalConfig](),
+	)
+
+	config := loader.Load()
+	fmt.Println("Database Host:", config.DatabaseConfig.Host)
+	fmt.Println("Database Port:", config.DatabaseConfig.Port)
+	// Output:
+	// Database Host: sops.example.com
+	// Database Port: 5432
+}
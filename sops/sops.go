@@ -0,0 +1,54 @@
+// Package sops decrypts SOPS encrypted configs for schneider.vip/config. It is
+// a module of its own, as SOPS depends on the SDKs of the AWS, GCP and Azure
+// key management services.
+package sops
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/getsops/sops/v3/cmd/sops/formats"
+	"github.com/getsops/sops/v3/decrypt"
+	"schneider.vip/config"
+)
+
+// marker is contained in the encrypted values of SOPS files.
+var marker = []byte("ENC[AES256_GCM,")
+
+// WithSOPS is an option to decrypt SOPS encrypted config files, readers
+// and sources before parsing. Files without SOPS metadata are read as is. The
+// keys are configured like for the sops binary, e.g. SOPS_AGE_KEY_FILE for
+// age, the AWS, GCP or Azure credentials for KMS and the GnuPG keyring for PGP.
+// YAML, JSON, INI and dotenv files are decrypted in their format, files of
+// other formats in the binary format of SOPS.
+func WithSOPS[T any]() config.Option[T] {
+	return config.WithDecrypter[T](config.DecrypterFunc(Decrypt))
+}
+
+// Decrypt decrypts the data of the format, e.g. "yaml", if it contains SOPS
+// encrypted values.
+func Decrypt(data []byte, configType string) ([]byte, error) {
+	if !bytes.Contains(data, marker) {
+		return data, nil
+	}
+
+	format := formats.Binary
+
+	switch configType {
+	case "yaml", "yml":
+		format = formats.Yaml
+	case "json":
+		format = formats.Json
+	case "ini":
+		format = formats.Ini
+	case "env", "dotenv":
+		format = formats.Dotenv
+	}
+
+	plain, err := decrypt.DataWithFormat(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt SOPS data: %w", err)
+	}
+
+	return plain, nil
+}
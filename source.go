@@ -0,0 +1,377 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Source is a configuration source, like a remote key/value store. Its data is
+// merged on top of the config files. Implement it to load the config from
+// backends without first-party support and add it with WithSource.
+type Source interface {
+	// Read returns the config data and its format, e.g. "yaml".
+	Read() ([]byte, string, error)
+	// Watch returns a channel, which receives a value whenever the config
+	// changed. The channel is closed when ctx is done.
+	Watch(ctx context.Context) <-chan struct{}
+}
+
+// WithSource is an option to add a custom source. Its data is read on load and
+// merged on top of the config files, StartWatcher reloads the config whenever
+// the Watch channel of the source receives a value.
+func WithSource[T any](s Source) Option[T] {
+	return func(cl *loader[T]) {
+		cl.addSource(s)
+	}
+}
+
+// addSource adds a source to the loader.
+func (c *loader[T]) addSource(s Source) {
+	c.useDefaultFilename = false
+	c.sources = append(c.sources, s)
+}
+
+// mergeSources reads all sources and merges them on top of the config.
+func (c *loader[T]) mergeSources() error {
+	for i, s := range c.sources {
+		data, configType, err := c.readSource(i, s)
+		if err != nil {
+			return err
+		}
+
+		settings, err := c.decodeConfig(data, configType, "")
+		if err != nil {
+			return err
+		}
+
+		c.setOrigins(settings, SourceInfo{Kind: KindSource, Name: sourceName(i, s)})
+
+		if err := c.viper.MergeConfigMap(settings); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// watchSources triggers a reload whenever a source changed.
+func (c *loader[T]) watchSources() {
+	for _, s := range c.sources {
+		go func() {
+			for range s.Watch(c.ctx) {
+				c.triggerReload(triggerSource)
+			}
+		}()
+	}
+}
+
+// errSource is a source which could not be set up, reading it returns err.
+type errSource struct {
+	err error
+}
+
+func (s errSource) Read() ([]byte, string, error) {
+	return nil, "", s.err
+}
+
+func (s errSource) Watch(context.Context) <-chan struct{} {
+	changes := make(chan struct{})
+	close(changes)
+
+	return changes
+}
+
+// setPath sets the value in settings at the path of nested keys.
+func setPath(settings map[string]any, keys []string, value any) {
+	for _, key := range keys[:len(keys)-1] {
+		nested, ok := settings[key].(map[string]any)
+		if !ok {
+			nested = make(map[string]any)
+			settings[key] = nested
+		}
+
+		settings = nested
+	}
+
+	settings[keys[len(keys)-1]] = value
+}
+
+// decodeConfig decodes config data of the given format into a map.
+func decodeConfig(data []byte, configType string) (map[string]any, error) {
+	if decode, ok := decoder(configType); ok {
+		return decode(data)
+	}
+
+	v := viper.NewWithOptions(viper.KeyDelimiter(keyDelimiter))
+	v.SetConfigType(configType)
+
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+
+	return v.AllSettings(), nil
+}
+
+// pollChanges reads the data in the interval and sends a change whenever it
+// differs from the previous read. Read errors also send a change, so the
+// error is reported by the reload.
+func pollChanges(ctx context.Context, interval time.Duration, read func() ([]byte, error)) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+
+	go func() {
+		defer close(changes)
+
+		lastSum := [sha256.Size]byte{}
+		if data, err := read(); err == nil {
+			lastSum = sha256.Sum256(data)
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			data, err := read()
+			if err != nil {
+				notify(changes)
+
+				continue
+			}
+
+			if sum := sha256.Sum256(data); sum != lastSum {
+				lastSum = sum
+				notify(changes)
+			}
+		}
+	}()
+
+	return changes
+}
+
+// nestSection nests the settings under the section, nested sections are
+// separated by the key delimiter.
+func nestSection(section string, settings map[string]any) map[string]any {
+	if section == "" {
+		return settings
+	}
+
+	keys := strings.Split(strings.ToLower(section), keyDelimiter)
+	for i := len(keys) - 1; i >= 0; i-- {
+		settings = map[string]any{keys[i]: settings}
+	}
+
+	return settings
+}
+
+// notify sends a change notification without blocking, pending
+// notifications are coalesced.
+func notify(changes chan<- struct{}) {
+	select {
+	case changes <- struct{}{}:
+	default:
+	}
+}
+
+// defaultRemoteTimeout is the default timeout of reads of remote sources, so
+// a stalled endpoint does not block New or a reload.
+const defaultRemoteTimeout = 10 * time.Second
+
+// RemoteOption is a functional option for remote sources.
+type RemoteOption func(*remoteOptions)
+
+// remoteOptions are the options shared by all remote sources.
+type remoteOptions struct {
+	configType   string        // format of the config data, derived from the key if empty
+	pollInterval time.Duration // interval to poll or to retry a broken watch
+	timeout      time.Duration // timeout of a read and of connecting
+	httpClient   *http.Client
+	username     string
+	password     string
+	token        string
+	section      string // section to merge the data into
+	region       string // cloud region, taken from the environment if empty
+	endpoint     string // overrides the service endpoint
+}
+
+// newRemoteOptions applies opts to the default remote options.
+func newRemoteOptions(key string, opts []RemoteOption) remoteOptions {
+	o := remoteOptions{
+		pollInterval: 30 * time.Second,
+		timeout:      defaultRemoteTimeout,
+		httpClient:   http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.configType == "" {
+		o.configType = configTypeFromPath(key)
+	}
+
+	return o
+}
+
+// configTypeFromPath returns the config format by the extension of path,
+// defaults to "json".
+func configTypeFromPath(path string) string {
+	if ext := strings.TrimPrefix(configExt(path), "."); ext != "" {
+		return ext
+	}
+
+	return "json"
+}
+
+// WithFormat is a remote option to set the format of the config data, e.g.
+// "yaml". By default the format is derived from the extension of the key.
+func WithFormat(configType string) RemoteOption {
+	return func(o *remoteOptions) {
+		o.configType = configType
+	}
+}
+
+// WithPollInterval is a remote option to set the interval to poll for changes
+// and to retry broken watches. Defaults to 30 seconds.
+func WithPollInterval(interval time.Duration) RemoteOption {
+	return func(o *remoteOptions) {
+		o.pollInterval = interval
+	}
+}
+
+// WithTimeout is a remote option to set the timeout of a read of the source
+// and of connecting to it, 10 seconds by default. Watches are not limited.
+func WithTimeout(timeout time.Duration) RemoteOption {
+	return func(o *remoteOptions) {
+		o.timeout = timeout
+	}
+}
+
+// readContext returns the context of a read of the source, it is canceled
+// after the timeout.
+func (o remoteOptions) readContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), o.timeout)
+}
+
+// WithHTTPClient is a remote option to set the HTTP client, e.g. for TLS.
+func WithHTTPClient(client *http.Client) RemoteOption {
+	return func(o *remoteOptions) {
+		o.httpClient = client
+	}
+}
+
+// WithBasicAuth is a remote option to authenticate with username and password.
+func WithBasicAuth(username, password string) RemoteOption {
+	return func(o *remoteOptions) {
+		o.username = username
+		o.password = password
+	}
+}
+
+// WithSection is a remote option to merge the data of a secret source under a
+// section of the config, e.g. "databaseConfig" for the database credentials.
+func WithSection(section string) RemoteOption {
+	return func(o *remoteOptions) {
+		o.section = section
+	}
+}
+
+// WithRegion is a remote option to set the region of a cloud source. By default
+// the region is taken from the environment, e.g. AWS_REGION.
+func WithRegion(region string) RemoteOption {
+	return func(o *remoteOptions) {
+		o.region = region
+	}
+}
+
+// WithEndpoint is a remote option to override the service endpoint of a cloud
+// source, e.g. for emulators like LocalStack.
+func WithEndpoint(endpoint string) RemoteOption {
+	return func(o *remoteOptions) {
+		o.endpoint = endpoint
+	}
+}
+
+// WithBearerToken is a remote option to authenticate with a token.
+func WithBearerToken(token string) RemoteOption {
+	return func(o *remoteOptions) {
+		o.token = token
+	}
+}
+
+// postJSON posts request as JSON and returns the body of a successful response.
+func postJSON(ctx context.Context, client *http.Client, url, authorization string, request any) (io.ReadCloser, error) {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	resp, err := doRequest(client, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+// doRequest sends the request and returns the response if the status is OK.
+func doRequest(client *http.Client, req *http.Request) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+
+		return nil, &statusError{url: req.URL.Redacted(), status: resp.Status, code: resp.StatusCode}
+	}
+
+	return resp, nil
+}
+
+// statusError is an unexpected status of an HTTP response, it matches
+// errUnexpectedStatus.
+type statusError struct {
+	url    string
+	status string
+	code   int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s: %s %s", errUnexpectedStatus, e.url, e.status)
+}
+
+func (e *statusError) Is(target error) bool {
+	return target == errUnexpectedStatus
+}
+
+var (
+	errUnexpectedStatus   = errors.New("unexpected status")
+	errUnexpectedResponse = errors.New("unexpected response")
+)
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	errSQLColumns = errors.New("sql config query must return one or two columns")
+	errSQLRows    = errors.New("sql config document query must return exactly one row")
+)
+
+// WithSQL is an option to load the config from a database table. The query
+// returns either key/value rows, where nested keys are separated by "_" like
+// "databaseConfig_host" and NULL values are skipped, or a single row with a config document in one column,
+// e.g. a JSON column, other row counts of a document query are errors. The format of a document is JSON unless WithFormat is
+// used. StartWatcher runs the query in the poll interval and reloads if the
+// result changed. The database driver must be imported by the application.
+//
+//	config.WithSQL[GlobalConfig](db, "SELECT name, value FROM settings WHERE app = 'myapp'")
+func WithSQL[T any](db *sql.DB, query string, opts ...RemoteOption) Option[T] {
+	return func(cl *loader[T]) {
+		cl.addSource(&sqlSource{
+			db:    db,
+			query: query,
+			opts:  newRemoteOptions("", opts),
+		})
+	}
+}
+
+// sqlSource reads the config from the result of a query.
+type sqlSource struct {
+	db    *sql.DB
+	query string
+	opts  remoteOptions
+}
+
+// Read runs the query and returns the key/values as JSON or the document.
+func (s *sqlSource) Read() ([]byte, string, error) {
+	ctx, cancel := s.opts.readContext()
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, s.query)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to query config: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch len(columns) {
+	case 1:
+		var document []byte
+
+		count := 0
+		for rows.Next() {
+			if count++; count > 1 {
+				break
+			}
+
+			if err := rows.Scan(&document); err != nil {
+				return nil, "", fmt.Errorf("failed to read config: %w", err)
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			return nil, "", fmt.Errorf("failed to read config: %w", err)
+		}
+
+		if count != 1 {
+			return nil, "", errSQLRows
+		}
+
+		return document, s.opts.configType, nil
+	case 2:
+		settings := make(map[string]any)
+
+		for rows.Next() {
+			var key, value sql.NullString
+			if err := rows.Scan(&key, &value); err != nil {
+				return nil, "", fmt.Errorf("failed to read config: %w", err)
+			}
+
+			if key.String != "" && value.Valid {
+				setPath(settings, strings.Split(strings.ToLower(key.String), keyDelimiter), value.String)
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			return nil, "", fmt.Errorf("failed to read config: %w", err)
+		}
+
+		data, err := json.Marshal(nestSection(s.opts.section, settings))
+		if err != nil {
+			return nil, "", err
+		}
+
+		return data, "json", nil
+	default:
+		return nil, "", errSQLColumns
+	}
+}
+
+// Watch runs the query in the poll interval and sends a change if the result
+// changed.
+func (s *sqlSource) Watch(ctx context.Context) <-chan struct{} {
+	return pollChanges(ctx, s.opts.pollInterval, func() ([]byte, error) {
+		data, _, err := s.Read()
+
+		return data, err
+	})
+}
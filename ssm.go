@@ -0,0 +1,102 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"schneider.vip/config/internal/aws"
+)
+
+// WithSSM is an option to load a parameter tree of the AWS SSM Parameter Store.
+// The parameter names below path map to config keys, e.g. the parameter
+// "/myapp/prod/databaseConfig/host" of path "/myapp/prod" sets databaseConfig.host.
+// SecureString parameters are decrypted. Credentials are taken from the default
+// AWS chain, StartWatcher polls the parameters in the poll interval.
+func WithSSM[T any](path string, opts ...RemoteOption) Option[T] {
+	return func(cl *loader[T]) {
+		o := newRemoteOptions("", opts)
+
+		client, err := aws.NewClient(o.httpClient, o.region, o.endpoint)
+		if err != nil {
+			cl.addSource(errSource{err: err})
+
+			return
+		}
+
+		cl.addSource(&ssmSource{
+			path:   "/" + strings.Trim(path, "/"),
+			opts:   o,
+			client: client,
+		})
+	}
+}
+
+// ssmSource reads a parameter tree of the SSM Parameter Store.
+type ssmSource struct {
+	path   string
+	opts   remoteOptions
+	client *aws.Client
+}
+
+// Read returns all parameters below the path as JSON.
+func (s *ssmSource) Read() ([]byte, string, error) {
+	ctx, cancel := s.opts.readContext()
+	defer cancel()
+
+	settings := make(map[string]any)
+
+	var nextToken string
+
+	for {
+		input := map[string]any{
+			"Path":           s.path,
+			"Recursive":      true,
+			"WithDecryption": true,
+		}
+
+		if nextToken != "" {
+			input["NextToken"] = nextToken
+		}
+
+		var output struct {
+			Parameters []struct {
+				Name  string `json:"Name"`
+				Value string `json:"Value"`
+			} `json:"Parameters"`
+			NextToken string `json:"NextToken"`
+		}
+
+		err := s.client.CallJSON(ctx, "ssm", "AmazonSSM.GetParametersByPath", input, &output)
+		if err != nil {
+			return nil, "", err
+		}
+
+		for _, parameter := range output.Parameters {
+			name := strings.Trim(strings.TrimPrefix(parameter.Name, s.path), "/")
+			if name != "" {
+				setPath(settings, strings.Split(strings.ToLower(name), "/"), parameter.Value)
+			}
+		}
+
+		if nextToken = output.NextToken; nextToken == "" {
+			break
+		}
+	}
+
+	data, err := json.Marshal(nestSection(s.opts.section, settings))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, "json", nil
+}
+
+// Watch polls the parameters for changes.
+func (s *ssmSource) Watch(ctx context.Context) <-chan struct{} {
+	return pollChanges(ctx, s.opts.pollInterval, func() ([]byte, error) {
+		data, _, err := s.Read()
+
+		return data, err
+	})
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"maps"
+	"sync"
+	"time"
+)
+
+// loadStats are the statistics of the parses and reloads of a loader, used
+// for metrics and health checks.
+type loadStats struct {
+	mu             sync.Mutex
+	reloads        int                  // reload attempts
+	reloadFailures int                  // failed reloads
+	lastReload     time.Time            // time of the last reload attempt
+	lastSuccess    time.Time            // time of the last successful parse
+	checksum       string               // checksum of the effective config of the last successful parse
+	parseDuration  time.Duration        // duration of the last parse
+	reloadErr      error                // error of the last reload, nil after a successful parse
+	initialErr     error                // error of the initial parse if the default config is used, nil after a successful parse
+	unreachable    map[string]time.Time // sources by name which are unreachable since the time
+}
+
+// recordParse records a parse, the checksum is empty if it failed.
+func (s *loadStats) recordParse(duration time.Duration, checksum string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.parseDuration = duration
+
+	if checksum != "" {
+		s.lastSuccess = time.Now()
+		s.checksum = checksum
+		s.reloadErr = nil
+		s.initialErr = nil
+	}
+}
+
+// recordDefault records that the default config is used, as the initial parse
+// failed with err.
+func (s *loadStats) recordDefault(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.initialErr = err
+}
+
+// recordReload records a reload attempt.
+func (s *loadStats) recordReload(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reloads++
+	s.lastReload = time.Now()
+
+	s.reloadErr = err
+
+	if err != nil {
+		s.reloadFailures++
+	}
+}
+
+// recordSource records a read of the source with the name.
+func (s *loadStats) recordSource(name string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err == nil {
+		delete(s.unreachable, name)
+
+		return
+	}
+
+	if s.unreachable == nil {
+		s.unreachable = make(map[string]time.Time)
+	}
+
+	if _, ok := s.unreachable[name]; !ok {
+		s.unreachable[name] = time.Now()
+	}
+}
+
+// snapshot returns a copy of the statistics.
+func (s *loadStats) snapshot() loadStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return loadStats{
+		reloads:        s.reloads,
+		reloadFailures: s.reloadFailures,
+		lastReload:     s.lastReload,
+		lastSuccess:    s.lastSuccess,
+		checksum:       s.checksum,
+		parseDuration:  s.parseDuration,
+		reloadErr:      s.reloadErr,
+		initialErr:     s.initialErr,
+		unreachable:    maps.Clone(s.unreachable),
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+)
+
+// WithStdin is an option to load the config of the given format, e.g. "yaml",
+// from the standard input, like "mytool < config.yml". Standard input is read
+// once, reloads use the same data. If standard input is a terminal, nothing is
+// read instead of waiting for input.
+func WithStdin[T any](format string) Option[T] {
+	return func(cl *loader[T]) {
+		cl.addSource(&stdinSource{file: os.Stdin, format: format})
+	}
+}
+
+// stdinSource reads the config from standard input.
+type stdinSource struct {
+	file   *os.File
+	format string
+
+	once sync.Once
+	data []byte
+	err  error
+}
+
+// Read returns the data read from standard input.
+func (s *stdinSource) Read() ([]byte, string, error) {
+	s.once.Do(func() {
+		if info, err := s.file.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			return
+		}
+
+		s.data, s.err = io.ReadAll(s.file)
+	})
+
+	if s.err != nil {
+		return nil, "", s.err
+	}
+
+	if len(s.data) == 0 {
+		// empty documents are invalid JSON
+		return []byte("{}"), "json", nil
+	}
+
+	return s.data, s.format, nil
+}
+
+// Watch returns a closed channel, standard input is not watched.
+func (s *stdinSource) Watch(context.Context) <-chan struct{} {
+	changes := make(chan struct{})
+	close(changes)
+
+	return changes
+}
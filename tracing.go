@@ -0,0 +1,62 @@
+package config
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// tracerName is the name of the tracer of the loader.
+const tracerName = "schneider.vip/config"
+
+// WithTracerProvider is an option to trace the loader with OpenTelemetry: the
+// initial load in New ("config.Load") and every reload ("config.Reload"),
+// including the time to read the sources, with a "config.Parse" child span.
+// Reloads which change the config add a "config.changed" event with the
+// changed keys.
+func WithTracerProvider[T any](provider trace.TracerProvider) Option[T] {
+	return func(cl *loader[T]) {
+		cl.tracer = provider.Tracer(tracerName)
+	}
+}
+
+// startSpan starts a span, without a tracer the span is not recorded.
+func (c *loader[T]) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	tracer := c.tracer
+	if tracer == nil {
+		tracer = noop.NewTracerProvider().Tracer(tracerName)
+	}
+
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends the span, with an error status if err is not nil.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// changeEvent adds the "config.changed" event to the span if the config
+// changed. The values are not recorded, only the keys.
+func changeEvent(span trace.Span, diff []Change) {
+	if len(diff) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(diff))
+	for _, change := range diff {
+		keys = append(keys, change.Path)
+	}
+
+	span.AddEvent("config.changed", trace.WithAttributes(
+		attribute.Int("config.changes", len(diff)),
+		attribute.StringSlice("config.keys", keys),
+	))
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"slices"
+	"strings"
+)
+
+// UnknownKeys returns the keys of the config files and sources of the last
+// parsed config, which did not map to a field, e.g. because of typos. The keys
+// are nested with "_" like for Provenance, e.g. "databaseconfig_hots".
+func (c *loader[T]) UnknownKeys() []string {
+	keys := c.unknownKeys.Load()
+	if keys == nil {
+		return nil
+	}
+
+	return slices.Clone(*keys)
+}
+
+// storeUnknownKeys stores the keys of the config files and sources in the
+// unused keys of the decoder. Aliases and the version of migrations are known.
+func (c *loader[T]) storeUnknownKeys(unused []string) {
+	var keys []string
+
+	for _, key := range unused {
+		// the decoder separates nested keys with "." and keeps the case of tags
+		key = strings.ToLower(strings.ReplaceAll(key, ".", keyDelimiter))
+		if c.subSection != "" {
+			key = strings.ToLower(c.subSection) + keyDelimiter + key
+		}
+
+		if key == versionKey && len(c.migrations) > 0 {
+			continue
+		}
+
+		// unknown sections are reported by their nested keys
+		for origin := range c.origins {
+			if (origin == key || strings.HasPrefix(origin, key+keyDelimiter)) && !c.isAlias(origin) {
+				keys = append(keys, origin)
+			}
+		}
+	}
+
+	slices.Sort(keys)
+	c.unknownKeys.Store(&keys)
+}
+
+// isAlias reports whether the key is an alias or nested in one.
+func (c *loader[T]) isAlias(key string) bool {
+	for alias := range c.aliases {
+		if key == alias || strings.HasPrefix(key, alias+keyDelimiter) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// WithConfigURL is an option to load the config from an HTTP(S) URL. The
+// requests are conditional with ETag and Last-Modified, StartWatcher polls the
+// URL in the poll interval and reloads only if the content changed.
+// The format is derived from the URL extension, e.g. ".yml", unless WithFormat
+// is used. WithBasicAuth and WithBearerToken authenticate the requests.
+func WithConfigURL[T any](rawURL string, opts ...RemoteOption) Option[T] {
+	return func(cl *loader[T]) {
+		cl.addSource(URLSource(rawURL, opts...))
+	}
+}
+
+// URLSource returns the source of WithConfigURL, e.g. for WithSources.
+func URLSource(rawURL string, opts ...RemoteOption) Source {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return errSource{err: err}
+	}
+
+	o := newRemoteOptions(u.Path, opts)
+
+	return &urlSource{
+		url:       u.String(),
+		opts:      o,
+		authorize: o.authorize,
+	}
+}
+
+// urlSource reads the config from a URL.
+type urlSource struct {
+	url       string
+	opts      remoteOptions
+	authorize func(*http.Request) error // sets the credentials of the request
+
+	mu           sync.Mutex
+	data         []byte // body of the last successful response
+	etag         string
+	lastModified string
+}
+
+// Read returns the body of the URL, or the previous body if it is not modified.
+func (s *urlSource) Read() ([]byte, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, cancel := s.opts.readContext()
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if s.data != nil {
+		if s.etag != "" {
+			req.Header.Set("If-None-Match", s.etag)
+		}
+
+		if s.lastModified != "" {
+			req.Header.Set("If-Modified-Since", s.lastModified)
+		}
+	}
+
+	if err := s.authorize(req); err != nil {
+		return nil, "", err
+	}
+
+	resp, err := s.opts.httpClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch config URL: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return s.data, s.opts.configType, nil
+	default:
+		return nil, "", &statusError{url: req.URL.Redacted(), status: resp.Status, code: resp.StatusCode}
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch config URL: %w", err)
+	}
+
+	s.data = data
+	s.etag = resp.Header.Get("ETag")
+	s.lastModified = resp.Header.Get("Last-Modified")
+
+	return data, s.opts.configType, nil
+}
+
+// authorize sets the bearer token or basic auth credentials of the request.
+func (o remoteOptions) authorize(req *http.Request) error {
+	switch {
+	case o.token != "":
+		req.Header.Set("Authorization", "Bearer "+o.token)
+	case o.username != "":
+		req.SetBasicAuth(o.username, o.password)
+	}
+
+	return nil
+}
+
+// Watch polls the URL for changes.
+func (s *urlSource) Watch(ctx context.Context) <-chan struct{} {
+	return pollChanges(ctx, s.opts.pollInterval, func() ([]byte, error) {
+		data, _, err := s.Read()
+
+		return data, err
+	})
+}
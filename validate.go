@@ -0,0 +1,19 @@
+package config
+
+// ValidateFile reads the config file with the options, e.g. validators and
+// hooks, and returns an error if it cannot be read or parsed, e.g. in CI jobs
+// or pre-commit hooks before configs are deployed. Only the file is read:
+// other files, readers and sources of the options, profiles and automatic
+// environment variables are ignored. Unlike New it does not panic and never watches.
+func ValidateFile[T any](path string, opts ...Option[T]) error {
+	cl := newLoader(opts...)
+
+	cl.configFiles, cl.configDir, cl.readerConfig, cl.sources = nil, "", nil, nil
+	cl.viper.SetConfigFile(path)
+
+	if err := cl.readInConfig(); err != nil {
+		return cl.redactError(err)
+	}
+
+	return cl.Parse()
+}
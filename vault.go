@@ -0,0 +1,208 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// vaultServiceAccountToken is the token file of the Kubernetes service account.
+const vaultServiceAccountToken = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// VaultAuth authenticates against HashiCorp Vault.
+type VaultAuth interface {
+	// Login returns a client token and its lease duration, which is zero if
+	// the token does not expire.
+	Login(ctx context.Context, client *http.Client, address string) (string, time.Duration, error)
+}
+
+// VaultToken authenticates with a static client token.
+func VaultToken(token string) VaultAuth {
+	return vaultTokenAuth(token)
+}
+
+type vaultTokenAuth string
+
+func (t vaultTokenAuth) Login(context.Context, *http.Client, string) (string, time.Duration, error) {
+	return string(t), 0, nil
+}
+
+// VaultAppRole authenticates with the AppRole auth method mounted at "approle".
+func VaultAppRole(roleID, secretID string) VaultAuth {
+	return vaultLoginAuth{
+		mount: "approle",
+		request: func() (map[string]any, error) {
+			return map[string]any{"role_id": roleID, "secret_id": secretID}, nil
+		},
+	}
+}
+
+// VaultKubernetes authenticates with the Kubernetes auth method mounted at
+// "kubernetes", using the token of the pods service account.
+func VaultKubernetes(role string) VaultAuth {
+	return vaultLoginAuth{
+		mount: "kubernetes",
+		request: func() (map[string]any, error) {
+			jwt, err := os.ReadFile(vaultServiceAccountToken)
+			if err != nil {
+				return nil, err
+			}
+
+			return map[string]any{"role": role, "jwt": strings.TrimSpace(string(jwt))}, nil
+		},
+	}
+}
+
+// vaultLoginAuth logs in with an auth method.
+type vaultLoginAuth struct {
+	mount   string
+	request func() (map[string]any, error)
+}
+
+func (a vaultLoginAuth) Login(ctx context.Context, client *http.Client, address string) (string, time.Duration, error) {
+	request, err := a.request()
+	if err != nil {
+		return "", 0, err
+	}
+
+	body, err := postJSON(ctx, client, address+"/v1/auth/"+a.mount+"/login", "", request)
+	if err != nil {
+		return "", 0, fmt.Errorf("vault login failed: %w", err)
+	}
+	defer body.Close()
+
+	var response struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int    `json:"lease_duration"`
+		} `json:"auth"`
+	}
+
+	if err := json.NewDecoder(body).Decode(&response); err != nil {
+		return "", 0, fmt.Errorf("failed to decode vault login response: %w", err)
+	}
+
+	return response.Auth.ClientToken, time.Duration(response.Auth.LeaseDuration) * time.Second, nil
+}
+
+// WithVault is an option to load a KV v2 secret of HashiCorp Vault, e.g. path
+// "secret/data/myapp". The secret is refreshed in the poll interval and the
+// client token is renewed by logging in again before its lease expires.
+// Use WithSection to merge the secret into a section of the config.
+func WithVault[T any](address, path string, auth VaultAuth, opts ...RemoteOption) Option[T] {
+	return func(cl *loader[T]) {
+		cl.addSource(VaultSource(address, path, auth, opts...))
+	}
+}
+
+// VaultSource returns the source of WithVault, e.g. for WithSources.
+func VaultSource(address, path string, auth VaultAuth, opts ...RemoteOption) Source {
+	return &vaultSource{
+		address: strings.TrimSuffix(address, "/"),
+		path:    strings.TrimPrefix(path, "/"),
+		auth:    auth,
+		opts:    newRemoteOptions(path, opts),
+	}
+}
+
+// vaultSource reads a KV v2 secret of Vault.
+type vaultSource struct {
+	address     string
+	path        string
+	auth        VaultAuth
+	opts        remoteOptions
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// Read returns the secret data as JSON.
+func (s *vaultSource) Read() ([]byte, string, error) {
+	ctx, cancel := s.opts.readContext()
+	defer cancel()
+
+	secret, err := s.readSecret(ctx, s.path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	data, err := json.Marshal(nestSection(s.opts.section, secret))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, "json", nil
+}
+
+// readSecret returns the data of the KV v2 secret at path.
+func (s *vaultSource) readSecret(ctx context.Context, path string) (map[string]any, error) {
+	token, err := s.clientToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.address+"/v1/"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("X-Vault-Token", token)
+
+	resp, err := doRequest(s.opts.httpClient, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault secret: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode vault secret: %w", err)
+	}
+
+	return response.Data.Data, nil
+}
+
+// Watch polls the secret for changes.
+func (s *vaultSource) Watch(ctx context.Context) <-chan struct{} {
+	return pollChanges(ctx, s.opts.pollInterval, func() ([]byte, error) {
+		data, _, err := s.Read()
+
+		return data, err
+	})
+}
+
+// clientToken returns the client token, logging in if the token is missing
+// or its lease is about to expire.
+func (s *vaultSource) clientToken(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && (s.tokenExpiry.IsZero() || time.Now().Before(s.tokenExpiry)) {
+		return s.token, nil
+	}
+
+	token, leaseDuration, err := s.auth.Login(ctx, s.opts.httpClient, s.address)
+	if err != nil {
+		return "", err
+	}
+
+	s.token = token
+	s.tokenExpiry = time.Time{}
+
+	if leaseDuration > 0 {
+		// Renew after 2/3 of the lease, before the token expires
+		s.tokenExpiry = time.Now().Add(leaseDuration * 2 / 3)
+	}
+
+	return s.token, nil
+}
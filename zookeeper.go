@@ -0,0 +1,447 @@
+package config
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// zkSessionTimeout is the requested session timeout, pings are sent in a
+	// third of it.
+	zkSessionTimeout = 30 * time.Second
+
+	zkOpExists  = 3
+	zkOpGetData = 4
+	zkOpPing    = 11
+	zkOpAuth    = 100
+
+	zkXidWatch = -1
+	zkXidPing  = -2
+	zkXidAuth  = -4
+
+	zkErrNoNode = -101
+
+	// zkMaxPacketLength is the maximum length of a packet, the default
+	// jute.maxbuffer of ZooKeeper is 1 MiB.
+	zkMaxPacketLength = 16 << 20
+)
+
+var errZooKeeper = errors.New("zookeeper error")
+
+// WithZooKeeper is an option to load the config from the data of a ZooKeeper
+// znode. The znode is watched by StartWatcher and every change triggers a
+// reload. The servers ("host:2181") are tried in order. WithBasicAuth
+// authenticates with the digest scheme.
+// The format is derived from the znode extension, e.g. "/myapp/config.yml",
+// unless WithFormat is used.
+func WithZooKeeper[T any](servers []string, path string, opts ...RemoteOption) Option[T] {
+	return func(cl *loader[T]) {
+		cl.addSource(&zkSource{
+			servers: servers,
+			path:    path,
+			opts:    newRemoteOptions(path, opts),
+		})
+	}
+}
+
+// zkSource reads and watches a ZooKeeper znode.
+type zkSource struct {
+	servers []string
+	path    string
+	opts    remoteOptions
+}
+
+// Read returns the data of the znode.
+func (s *zkSource) Read() ([]byte, string, error) {
+	ctx, cancel := s.opts.readContext()
+	defer cancel()
+
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	defer conn.Close()
+
+	data, err := conn.getData(s.path, false)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, s.opts.configType, nil
+}
+
+// Watch sends a change for every change of the znode, a broken session is
+// retried in the poll interval.
+func (s *zkSource) Watch(ctx context.Context) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+
+	go func() {
+		defer close(changes)
+
+		for {
+			_ = s.watch(ctx, changes)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(s.opts.pollInterval):
+				notify(changes)
+			}
+		}
+	}()
+
+	return changes
+}
+
+// watch sets a watch on the znode and sends a change whenever it fires, until
+// the session breaks.
+func (s *zkSource) watch(ctx context.Context, changes chan<- struct{}) error {
+	dialCtx, cancel := context.WithTimeout(ctx, s.opts.timeout)
+	defer cancel()
+
+	conn, err := s.dial(dialCtx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	_ = conn.SetDeadline(time.Time{})
+	go conn.ping(ctx)
+
+	for {
+		// watches fire once, so they are set again after every event
+		if _, err := conn.getData(s.path, true); errors.Is(err, errKeyNotFound) {
+			if err := conn.exists(s.path); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+
+		if err := conn.waitEvent(); err != nil {
+			return err
+		}
+
+		notify(changes)
+	}
+}
+
+// dial connects to the first reachable server and authenticates.
+func (s *zkSource) dial(ctx context.Context) (*zkConn, error) {
+	var errs []error
+
+	for _, server := range s.servers {
+		conn, err := dialZooKeeper(ctx, server)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		if s.opts.username != "" {
+			if err := conn.auth("digest", s.opts.username+":"+s.opts.password); err != nil {
+				conn.Close()
+
+				return nil, err
+			}
+		}
+
+		return conn, nil
+	}
+
+	return nil, fmt.Errorf("failed to connect to zookeeper: %w", errors.Join(errs...))
+}
+
+// zkConn is a ZooKeeper session speaking the jute protocol.
+type zkConn struct {
+	net.Conn
+	reader  *bufio.Reader
+	writeMu sync.Mutex
+	xid     int32
+	timeout time.Duration
+}
+
+// dialZooKeeper connects to the server and creates a session.
+func dialZooKeeper(ctx context.Context, server string) (*zkConn, error) {
+	netConn, err := (&net.Dialer{}).DialContext(ctx, "tcp", server)
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = netConn.SetDeadline(deadline)
+	}
+
+	c := &zkConn{Conn: netConn, reader: bufio.NewReader(netConn)}
+
+	var request zkBuffer
+	request.int32(0)                                      // protocol version
+	request.int64(0)                                      // last zxid seen
+	request.int32(int32(zkSessionTimeout.Milliseconds())) // session timeout
+	request.int64(0)                                      // session id
+	request.bytes(make([]byte, 16))                       // password
+
+	if err := c.writePacket(request); err != nil {
+		netConn.Close()
+
+		return nil, err
+	}
+
+	response, err := c.readPacket()
+	if err != nil {
+		netConn.Close()
+
+		return nil, err
+	}
+
+	response.int32() // protocol version
+	c.timeout = time.Duration(response.int32()) * time.Millisecond
+
+	if c.timeout <= 0 {
+		netConn.Close()
+
+		return nil, fmt.Errorf("%w: session expired", errZooKeeper)
+	}
+
+	return c, nil
+}
+
+// getData returns the data of the znode and optionally sets a watch.
+func (c *zkConn) getData(path string, watch bool) ([]byte, error) {
+	var request zkBuffer
+	request.string(path)
+	request.bool(watch)
+
+	response, err := c.call(zkOpGetData, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read znode %s: %w", path, err)
+	}
+
+	return response.bytes(), nil
+}
+
+// exists sets a watch on the creation of the znode.
+func (c *zkConn) exists(path string) error {
+	var request zkBuffer
+	request.string(path)
+	request.bool(true)
+
+	if _, err := c.call(zkOpExists, request); err != nil && !errors.Is(err, errKeyNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+// auth adds the authentication of the scheme to the session.
+func (c *zkConn) auth(scheme, credentials string) error {
+	var request zkBuffer
+	request.int32(zkXidAuth)
+	request.int32(zkOpAuth)
+	request.int32(0)
+	request.string(scheme)
+	request.bytes([]byte(credentials))
+
+	if err := c.writePacket(request); err != nil {
+		return err
+	}
+
+	response, err := c.readPacket()
+	if err != nil {
+		return err
+	}
+
+	response.int32() // xid
+	response.int64() // zxid
+
+	if code := response.int32(); code != 0 {
+		return fmt.Errorf("%w: authentication failed: %d", errZooKeeper, code)
+	}
+
+	return nil
+}
+
+// call sends the request and returns the body of its reply. Pings and events
+// received meanwhile are skipped.
+func (c *zkConn) call(op int32, body zkBuffer) (*zkReader, error) {
+	c.xid++
+
+	var request zkBuffer
+	request.int32(c.xid)
+	request.int32(op)
+	request = append(request, body...)
+
+	if err := c.writePacket(request); err != nil {
+		return nil, err
+	}
+
+	for {
+		response, err := c.readPacket()
+		if err != nil {
+			return nil, err
+		}
+
+		xid := response.int32()
+		response.int64() // zxid
+		code := response.int32()
+
+		switch {
+		case xid != c.xid:
+			continue
+		case code == zkErrNoNode:
+			return nil, errKeyNotFound
+		case code != 0:
+			return nil, fmt.Errorf("%w: %d", errZooKeeper, code)
+		}
+
+		return response, nil
+	}
+}
+
+// waitEvent waits for a watch event.
+func (c *zkConn) waitEvent() error {
+	for {
+		response, err := c.readPacket()
+		if err != nil {
+			return err
+		}
+
+		if response.int32() == zkXidWatch {
+			return nil
+		}
+	}
+}
+
+// ping keeps the session alive until ctx is done or the connection breaks.
+func (c *zkConn) ping(ctx context.Context) {
+	ticker := time.NewTicker(c.timeout / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		var request zkBuffer
+		request.int32(zkXidPing)
+		request.int32(zkOpPing)
+
+		if err := c.writePacket(request); err != nil {
+			return
+		}
+	}
+}
+
+// writePacket writes the length prefixed packet.
+func (c *zkConn) writePacket(packet zkBuffer) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	data := binary.BigEndian.AppendUint32(nil, uint32(len(packet)))
+	_, err := c.Write(append(data, packet...))
+
+	return err
+}
+
+// readPacket reads a length prefixed packet.
+func (c *zkConn) readPacket() (*zkReader, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(c.reader, size[:]); err != nil {
+		return nil, err
+	}
+
+	length := binary.BigEndian.Uint32(size[:])
+	if length > zkMaxPacketLength {
+		return nil, fmt.Errorf("%w: packet of %d bytes exceeds %d", errZooKeeper, length, zkMaxPacketLength)
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, data); err != nil {
+		return nil, err
+	}
+
+	return &zkReader{data: data}, nil
+}
+
+// zkBuffer encodes jute records.
+type zkBuffer []byte
+
+func (b *zkBuffer) int32(v int32) {
+	*b = binary.BigEndian.AppendUint32(*b, uint32(v))
+}
+
+func (b *zkBuffer) int64(v int64) {
+	*b = binary.BigEndian.AppendUint64(*b, uint64(v))
+}
+
+func (b *zkBuffer) bool(v bool) {
+	if v {
+		*b = append(*b, 1)
+	} else {
+		*b = append(*b, 0)
+	}
+}
+
+func (b *zkBuffer) bytes(v []byte) {
+	b.int32(int32(len(v)))
+	*b = append(*b, v...)
+}
+
+func (b *zkBuffer) string(v string) {
+	b.bytes([]byte(v))
+}
+
+// zkReader decodes jute records, reading past the end returns zero values.
+type zkReader struct {
+	data []byte
+}
+
+func (r *zkReader) int32() int32 {
+	if len(r.data) < 4 {
+		r.data = nil
+
+		return 0
+	}
+
+	v := int32(binary.BigEndian.Uint32(r.data))
+	r.data = r.data[4:]
+
+	return v
+}
+
+func (r *zkReader) int64() int64 {
+	if len(r.data) < 8 {
+		r.data = nil
+
+		return 0
+	}
+
+	v := int64(binary.BigEndian.Uint64(r.data))
+	r.data = r.data[8:]
+
+	return v
+}
+
+func (r *zkReader) bytes() []byte {
+	n := r.int32()
+	if n < 0 || int(n) > len(r.data) {
+		return nil
+	}
+
+	v := r.data[:n]
+	r.data = r.data[n:]
+
+	return v
+}